@@ -1,13 +1,88 @@
 package config
 
+import (
+	"os"
+	"strings"
+)
+
+// AuthToken grants whoever presents Token access to the websocket API,
+// scoped to PrinterName. An empty PrinterName means the token is allowed
+// to use any printer.
+type AuthToken struct {
+	Token       string
+	PrinterName string
+}
+
 type Config struct {
 	WebSocketPort string
 	HTTPPort      string
+
+	// TLSCertFile and TLSKeyFile enable TLS when both are set. When
+	// either is empty the server falls back to plain HTTP, which is
+	// the default for local development.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// AllowedOrigins lists the Origin header values the websocket
+	// upgrade accepts. An empty list disables the check (development
+	// default); non-browser clients that send no Origin header are
+	// always allowed.
+	AllowedOrigins []string
+
+	// AuthTokens lists the bearer tokens accepted by the websocket
+	// endpoint. An empty list disables authentication (development
+	// default).
+	AuthTokens []AuthToken
 }
 
 func Load() *Config {
 	return &Config{
-		WebSocketPort: ":8081",
-		HTTPPort:      ":8080",
+		WebSocketPort:  envOrDefault("PRINTER_SERVICE_WS_PORT", ":8081"),
+		HTTPPort:       envOrDefault("PRINTER_SERVICE_HTTP_PORT", ":8080"),
+		TLSCertFile:    os.Getenv("PRINTER_SERVICE_TLS_CERT"),
+		TLSKeyFile:     os.Getenv("PRINTER_SERVICE_TLS_KEY"),
+		AllowedOrigins: splitEnvList("PRINTER_SERVICE_ALLOWED_ORIGINS"),
+		AuthTokens:     loadAuthTokens(),
+	}
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func splitEnvList(key string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// loadAuthTokens parses PRINTER_SERVICE_AUTH_TOKENS, a comma-separated
+// list of "token" or "token:printerName" entries.
+func loadAuthTokens() []AuthToken {
+	v := os.Getenv("PRINTER_SERVICE_AUTH_TOKENS")
+	if v == "" {
+		return nil
+	}
+
+	var tokens []AuthToken
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		token, printerName, _ := strings.Cut(entry, ":")
+		tokens = append(tokens, AuthToken{Token: token, PrinterName: printerName})
 	}
+	return tokens
 }
@@ -9,6 +9,11 @@ import (
 
 func main() {
 	cfg := config.Load()
+	websocket.Configure(cfg)
+
+	if err := websocket.InitQueue("printer-jobs.db"); err != nil {
+		log.Fatal("Failed to initialize job queue:", err)
+	}
 
 	// WebSocket endpoint
 	http.HandleFunc("/ws", websocket.HandleWebSocket)
@@ -20,8 +25,16 @@ func main() {
 	})
 
 	log.Printf("Starting printer service on %s", cfg.WebSocketPort)
-	log.Printf("WebSocket available at ws://localhost%s/ws", cfg.WebSocketPort)
 
+	if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" {
+		log.Printf("WebSocket available at wss://localhost%s/ws", cfg.WebSocketPort)
+		if err := http.ListenAndServeTLS(cfg.WebSocketPort, cfg.TLSCertFile, cfg.TLSKeyFile, nil); err != nil {
+			log.Fatal("Server error:", err)
+		}
+		return
+	}
+
+	log.Printf("WebSocket available at ws://localhost%s/ws", cfg.WebSocketPort)
 	if err := http.ListenAndServe(cfg.WebSocketPort, nil); err != nil {
 		log.Fatal("Server error:", err)
 	}
@@ -0,0 +1,240 @@
+package printer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// mdnsServices maps the DNS-SD service types this service knows how to
+// turn into printer URIs: IPP printers and raw JetDirect/RAW 9100 "PDL
+// datastream" printers.
+var mdnsServices = map[string]string{
+	"_ipp._tcp.local.":            "ipp",
+	"_pdl-datastream._tcp.local.": "raw",
+}
+
+const mdnsMulticastAddr = "224.0.0.251:5353"
+
+// DiscoverNetworkPrinters sends mDNS/DNS-SD PTR queries for _ipp._tcp
+// and _pdl-datastream._tcp and collects responses for timeout, returning
+// one Printer per discovered instance with a raw:// or ipp:// URI as its
+// Name so it can be passed straight to Dispatch.
+func DiscoverNetworkPrinters(timeout time.Duration) ([]Printer, error) {
+	conn, err := net.ListenPacket("udp4", "0.0.0.0:0")
+	if err != nil {
+		return nil, fmt.Errorf("open mdns socket: %w", err)
+	}
+	defer conn.Close()
+
+	group, err := net.ResolveUDPAddr("udp4", mdnsMulticastAddr)
+	if err != nil {
+		return nil, fmt.Errorf("resolve mdns multicast address: %w", err)
+	}
+
+	for service := range mdnsServices {
+		if _, err := conn.WriteTo(buildMDNSQuery(service), group); err != nil {
+			return nil, fmt.Errorf("send mdns query for %s: %w", service, err)
+		}
+	}
+
+	var printers []Printer
+	deadline := time.Now().Add(timeout)
+	buf := make([]byte, 8192)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		conn.SetReadDeadline(time.Now().Add(remaining))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break // deadline reached
+		}
+		printers = append(printers, parseMDNSResponse(buf[:n])...)
+	}
+
+	return printers, nil
+}
+
+// buildMDNSQuery encodes a single-question mDNS query (QTYPE=PTR,
+// QCLASS=IN) for service.
+func buildMDNSQuery(service string) []byte {
+	var msg []byte
+	msg = append(msg, 0x00, 0x00) // transaction ID (ignored for mDNS)
+	msg = append(msg, 0x00, 0x00) // flags: standard query
+	msg = append(msg, 0x00, 0x01) // QDCOUNT = 1
+	msg = append(msg, 0x00, 0x00) // ANCOUNT
+	msg = append(msg, 0x00, 0x00) // NSCOUNT
+	msg = append(msg, 0x00, 0x00) // ARCOUNT
+	msg = append(msg, encodeDNSName(service)...)
+	msg = append(msg, 0x00, 0x0C) // QTYPE = PTR (12)
+	msg = append(msg, 0x00, 0x01) // QCLASS = IN
+	return msg
+}
+
+func encodeDNSName(name string) []byte {
+	var out []byte
+	start := 0
+	for i := 0; i <= len(name); i++ {
+		if i == len(name) || name[i] == '.' {
+			if i > start {
+				label := name[start:i]
+				out = append(out, byte(len(label)))
+				out = append(out, label...)
+			}
+			start = i + 1
+		}
+	}
+	return append(out, 0x00)
+}
+
+// mdnsRecord is one resource record from a parsed mDNS response, with
+// its rdata left as an (offset, length) pair into the original packet so
+// compressed names inside rdata can still be decoded against the full
+// message.
+type mdnsRecord struct {
+	name      string
+	rrtype    uint16
+	dataStart int
+	dataLen   int
+}
+
+// parseMDNSResponse extracts PTR answers for known service types and
+// resolves a best-effort host:port for each from SRV records present in
+// the same packet.
+func parseMDNSResponse(packet []byte) []Printer {
+	records, ok := parseMDNSRecords(packet)
+	if !ok {
+		return nil
+	}
+
+	hostPorts := make(map[string]string) // SRV owner name -> host:port
+	for _, r := range records {
+		if r.rrtype != 33 || r.dataLen < 7 { // SRV: priority, weight, port, target
+			continue
+		}
+		port := binary.BigEndian.Uint16(packet[r.dataStart+4 : r.dataStart+6])
+		target, _, ok := readDNSName(packet, r.dataStart+6)
+		if !ok {
+			continue
+		}
+		hostPorts[r.name] = fmt.Sprintf("%s:%d", target, port)
+	}
+
+	var printers []Printer
+	for _, r := range records {
+		if r.rrtype != 12 { // PTR
+			continue
+		}
+		scheme, known := mdnsServices[r.name]
+		if !known {
+			continue
+		}
+		instance, _, ok := readDNSName(packet, r.dataStart)
+		if !ok {
+			continue
+		}
+		hostPort, ok := hostPorts[instance]
+		if !ok {
+			continue
+		}
+
+		uri := fmt.Sprintf("raw://%s", hostPort)
+		if scheme == "ipp" {
+			uri = fmt.Sprintf("ipp://%s/", hostPort)
+		}
+		printers = append(printers, Printer{Name: uri, Status: "Discovered"})
+	}
+
+	return printers
+}
+
+// parseMDNSRecords walks the question section (discarding it) and
+// returns every answer/authority/additional record in packet.
+func parseMDNSRecords(packet []byte) ([]mdnsRecord, bool) {
+	if len(packet) < 12 {
+		return nil, false
+	}
+	qdcount := binary.BigEndian.Uint16(packet[4:6])
+	ancount := binary.BigEndian.Uint16(packet[6:8])
+	nscount := binary.BigEndian.Uint16(packet[8:10])
+	arcount := binary.BigEndian.Uint16(packet[10:12])
+
+	offset := 12
+	for i := 0; i < int(qdcount); i++ {
+		_, next, ok := readDNSName(packet, offset)
+		if !ok {
+			return nil, false
+		}
+		offset = next + 4 // QTYPE + QCLASS
+	}
+
+	var records []mdnsRecord
+	total := int(ancount) + int(nscount) + int(arcount)
+	for i := 0; i < total; i++ {
+		name, next, ok := readDNSName(packet, offset)
+		if !ok || next+10 > len(packet) {
+			break
+		}
+		rrtype := binary.BigEndian.Uint16(packet[next : next+2])
+		rdlength := int(binary.BigEndian.Uint16(packet[next+8 : next+10]))
+		dataStart := next + 10
+		if dataStart+rdlength > len(packet) {
+			break
+		}
+		records = append(records, mdnsRecord{name: name, rrtype: rrtype, dataStart: dataStart, dataLen: rdlength})
+		offset = dataStart + rdlength
+	}
+
+	return records, true
+}
+
+// readDNSName decodes a (possibly compressed) DNS name starting at
+// offset, returning the name, the offset immediately after it in the
+// original message, and whether decoding succeeded.
+func readDNSName(packet []byte, offset int) (string, int, bool) {
+	var labels []string
+	pos := offset
+	end := -1 // offset to resume at after following a pointer; -1 if none followed yet
+	jumps := 0
+
+	for {
+		if pos < 0 || pos >= len(packet) {
+			return "", 0, false
+		}
+		length := int(packet[pos])
+		if length == 0 {
+			pos++
+			break
+		}
+		if length&0xC0 == 0xC0 {
+			if pos+1 >= len(packet) {
+				return "", 0, false
+			}
+			if end == -1 {
+				end = pos + 2
+			}
+			pointer := int(binary.BigEndian.Uint16(packet[pos:pos+2]) & 0x3FFF)
+			pos = pointer
+			jumps++
+			if jumps > 16 {
+				return "", 0, false
+			}
+			continue
+		}
+		pos++
+		if pos+length > len(packet) {
+			return "", 0, false
+		}
+		labels = append(labels, string(packet[pos:pos+length]))
+		pos += length
+	}
+
+	if end == -1 {
+		end = pos
+	}
+	return strings.Join(labels, ".") + ".", end, true
+}
@@ -0,0 +1,110 @@
+package printer
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestEncodeDNSNameLabelLengths(t *testing.T) {
+	encoded := encodeDNSName("_ipp._tcp.local.")
+	want := []byte{4}
+	want = append(want, "_ipp"...)
+	want = append(want, 4)
+	want = append(want, "_tcp"...)
+	want = append(want, 5)
+	want = append(want, "local"...)
+	want = append(want, 0x00)
+
+	if string(encoded) != string(want) {
+		t.Fatalf("encodeDNSName = % x, want % x", encoded, want)
+	}
+}
+
+func TestReadDNSNameUncompressed(t *testing.T) {
+	packet := encodeDNSName("printer.local.")
+	name, next, ok := readDNSName(packet, 0)
+	if !ok {
+		t.Fatal("readDNSName reported failure on a well-formed name")
+	}
+	if name != "printer.local." {
+		t.Fatalf("name = %q, want %q", name, "printer.local.")
+	}
+	if next != len(packet) {
+		t.Fatalf("next = %d, want %d (end of the encoded name)", next, len(packet))
+	}
+}
+
+func TestReadDNSNameFollowsCompressionPointer(t *testing.T) {
+	// Lay out two names back to back, where the second is a pointer back
+	// to the first, exactly as DNS message compression does.
+	first := encodeDNSName("_ipp._tcp.local.")
+	pointer := []byte{0xC0, 0x00} // pointer to offset 0
+	packet := append(append([]byte{}, first...), pointer...)
+
+	name, next, ok := readDNSName(packet, len(first))
+	if !ok {
+		t.Fatal("readDNSName failed to follow a compression pointer")
+	}
+	if name != "_ipp._tcp.local." {
+		t.Fatalf("name = %q, want %q", name, "_ipp._tcp.local.")
+	}
+	if next != len(packet) {
+		t.Fatalf("next = %d, want %d (just past the 2-byte pointer)", next, len(packet))
+	}
+}
+
+func TestReadDNSNameRejectsPointerLoop(t *testing.T) {
+	// A pointer at offset 0 that points to itself must not hang.
+	packet := []byte{0xC0, 0x00}
+	if _, _, ok := readDNSName(packet, 0); ok {
+		t.Fatal("expected a self-referential compression pointer to be rejected")
+	}
+}
+
+func TestReadDNSNameOutOfBounds(t *testing.T) {
+	if _, _, ok := readDNSName([]byte{5, 'h', 'i'}, 0); ok {
+		t.Fatal("expected a label length overrunning the packet to fail")
+	}
+}
+
+func TestParseMDNSRecordsResolvesPTRViaSRV(t *testing.T) {
+	var packet []byte
+	packet = append(packet, 0x00, 0x00) // transaction ID
+	packet = append(packet, 0x00, 0x00) // flags
+	packet = append(packet, 0x00, 0x00) // QDCOUNT
+	packet = append(packet, 0x00, 0x02) // ANCOUNT = 2 (PTR + SRV)
+	packet = append(packet, 0x00, 0x00) // NSCOUNT
+	packet = append(packet, 0x00, 0x00) // ARCOUNT
+
+	// PTR record: _ipp._tcp.local. -> kitchen-printer._ipp._tcp.local.
+	packet = append(packet, encodeDNSName("_ipp._tcp.local.")...)
+	packet = append(packet, 0x00, 0x0C) // TYPE = PTR
+	packet = append(packet, 0x00, 0x01) // CLASS = IN
+	packet = append(packet, 0x00, 0x00, 0x00, 0x00) // TTL
+	instanceName := encodeDNSName("kitchen-printer._ipp._tcp.local.")
+	packet = append(packet, byte(len(instanceName)>>8), byte(len(instanceName)))
+	packet = append(packet, instanceName...)
+
+	// SRV record for the instance name, pointing at host:port.
+	packet = append(packet, encodeDNSName("kitchen-printer._ipp._tcp.local.")...)
+	packet = append(packet, 0x00, 0x21) // TYPE = SRV (33)
+	packet = append(packet, 0x00, 0x01) // CLASS = IN
+	packet = append(packet, 0x00, 0x00, 0x00, 0x00) // TTL
+
+	target := encodeDNSName("kitchen.local.")
+	rdata := []byte{0x00, 0x00, 0x00, 0x00} // priority(2), weight(2)
+	portBytes := make([]byte, 2)
+	binary.BigEndian.PutUint16(portBytes, 9100)
+	rdata = append(rdata, portBytes...)
+	rdata = append(rdata, target...)
+	packet = append(packet, byte(len(rdata)>>8), byte(len(rdata)))
+	packet = append(packet, rdata...)
+
+	printers := parseMDNSResponse(packet)
+	if len(printers) != 1 {
+		t.Fatalf("got %d printers, want 1: %+v", len(printers), printers)
+	}
+	if printers[0].Name != "ipp://kitchen.local:9100/" {
+		t.Fatalf("printer URI = %q, want %q", printers[0].Name, "ipp://kitchen.local:9100/")
+	}
+}
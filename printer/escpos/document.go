@@ -0,0 +1,164 @@
+package escpos
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Command is one step of a structured ESC/POS document submitted by a
+// client over the print_escpos websocket message. Params is decoded
+// according to Type.
+type Command struct {
+	Type   string          `json:"type"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Document is the structured JSON body accepted by the print_escpos
+// websocket message: a codepage plus an ordered list of builder commands,
+// compiled server-side into an ESC/POS byte stream.
+type Document struct {
+	Codepage string    `json:"codepage"`
+	Commands []Command `json:"commands"`
+}
+
+// Build compiles the document into an ESC/POS byte stream via Builder.
+func (d Document) Build() ([]byte, error) {
+	cp := CP437
+	if d.Codepage != "" {
+		var ok bool
+		cp, ok = CodepageByName(d.Codepage)
+		if !ok {
+			return nil, fmt.Errorf("escpos: unknown codepage %q", d.Codepage)
+		}
+	}
+
+	b := NewBuilder(cp)
+	for i, cmd := range d.Commands {
+		if err := applyCommand(b, cmd); err != nil {
+			return nil, fmt.Errorf("escpos: command %d (%s): %w", i, cmd.Type, err)
+		}
+	}
+	if b.Err() != nil {
+		return nil, b.Err()
+	}
+	return b.Bytes(), nil
+}
+
+func applyCommand(b *Builder, cmd Command) error {
+	switch cmd.Type {
+	case "text":
+		var p struct {
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal(cmd.Params, &p); err != nil {
+			return err
+		}
+		b.Text(p.Value)
+
+	case "align":
+		var p struct {
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal(cmd.Params, &p); err != nil {
+			return err
+		}
+		switch p.Value {
+		case "left":
+			b.Align(AlignLeft)
+		case "center":
+			b.Align(AlignCenter)
+		case "right":
+			b.Align(AlignRight)
+		default:
+			return fmt.Errorf("unknown align value %q", p.Value)
+		}
+
+	case "bold":
+		var p struct {
+			On bool `json:"on"`
+		}
+		if err := json.Unmarshal(cmd.Params, &p); err != nil {
+			return err
+		}
+		b.Bold(p.On)
+
+	case "underline":
+		var p struct {
+			On bool `json:"on"`
+		}
+		if err := json.Unmarshal(cmd.Params, &p); err != nil {
+			return err
+		}
+		b.Underline(p.On)
+
+	case "fontSize":
+		var p struct {
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		}
+		if err := json.Unmarshal(cmd.Params, &p); err != nil {
+			return err
+		}
+		b.FontSize(p.Width, p.Height)
+
+	case "barcode":
+		var p struct {
+			BarcodeType string `json:"barcodeType"`
+			Data        string `json:"data"`
+		}
+		if err := json.Unmarshal(cmd.Params, &p); err != nil {
+			return err
+		}
+		bt, ok := barcodeTypeNames[p.BarcodeType]
+		if !ok {
+			return fmt.Errorf("unknown barcode type %q", p.BarcodeType)
+		}
+		b.Barcode(bt, p.Data)
+
+	case "qrcode":
+		var p struct {
+			Data       string `json:"data"`
+			ModuleSize int    `json:"moduleSize"`
+		}
+		if err := json.Unmarshal(cmd.Params, &p); err != nil {
+			return err
+		}
+		b.QRCode(p.Data, p.ModuleSize)
+
+	case "image":
+		var p struct {
+			Data       string `json:"data"` // base64, pre-packed 1-bpp MSB-first raster data
+			WidthDots  int    `json:"widthDots"`
+			HeightDots int    `json:"heightDots"`
+		}
+		if err := json.Unmarshal(cmd.Params, &p); err != nil {
+			return err
+		}
+		raw, err := base64.StdEncoding.DecodeString(p.Data)
+		if err != nil {
+			return fmt.Errorf("decode image data: %w", err)
+		}
+		b.Image(raw, p.WidthDots, p.HeightDots)
+
+	case "cut":
+		b.Cut()
+
+	case "cashDrawer":
+		b.CashDrawer()
+
+	case "feedLines":
+		var p struct {
+			Lines int `json:"lines"`
+		}
+		if err := json.Unmarshal(cmd.Params, &p); err != nil {
+			return err
+		}
+		b.FeedLines(p.Lines)
+
+	default:
+		return fmt.Errorf("unknown command type %q", cmd.Type)
+	}
+
+	return b.Err()
+}
@@ -0,0 +1,82 @@
+package escpos
+
+import "testing"
+
+func TestDocumentBuildAppliesCommandsInOrder(t *testing.T) {
+	doc := Document{
+		Codepage: "CP437",
+		Commands: []Command{
+			{Type: "text", Params: []byte(`{"value":"hi"}`)},
+			{Type: "cut"},
+		},
+	}
+
+	data, err := doc.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if string(data[len(data)-5:len(data)-3]) != "hi" {
+		t.Fatalf("expected \"hi\" before the cut command, got % x", data)
+	}
+	if tail := data[len(data)-3:]; tail[0] != 0x1D || tail[1] != 0x56 {
+		t.Fatalf("expected a trailing cut command, got % x", tail)
+	}
+}
+
+func TestDocumentBuildDefaultsToCP437(t *testing.T) {
+	doc := Document{Commands: []Command{{Type: "cut"}}}
+	data, err := doc.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	// ESC t 0 is the CP437 codepage-select sequence.
+	if data[2] != 0x1B || data[3] != 0x74 || data[4] != 0 {
+		t.Fatalf("expected CP437 codepage select, got % x", data[2:5])
+	}
+}
+
+func TestDocumentBuildRejectsUnknownCodepage(t *testing.T) {
+	doc := Document{Codepage: "nonexistent", Commands: []Command{{Type: "cut"}}}
+	if _, err := doc.Build(); err == nil {
+		t.Fatal("expected an error for an unknown codepage")
+	}
+}
+
+func TestDocumentBuildRejectsUnknownCommandType(t *testing.T) {
+	doc := Document{Commands: []Command{{Type: "levitate"}}}
+	if _, err := doc.Build(); err == nil {
+		t.Fatal("expected an error for an unknown command type")
+	}
+}
+
+func TestDocumentBuildAlignCommand(t *testing.T) {
+	for value, want := range map[string]Alignment{"left": AlignLeft, "center": AlignCenter, "right": AlignRight} {
+		doc := Document{Commands: []Command{{Type: "align", Params: []byte(`{"value":"` + value + `"}`)}}}
+		data, err := doc.Build()
+		if err != nil {
+			t.Fatalf("Build(%s): %v", value, err)
+		}
+		tail := data[len(data)-3:]
+		if tail[0] != 0x1B || tail[1] != 0x61 || Alignment(tail[2]) != want {
+			t.Fatalf("align %s: got % x, want ESC a %d", value, tail, want)
+		}
+	}
+}
+
+func TestDocumentBuildImageCommandDecodesBase64(t *testing.T) {
+	// 1x8 raster image, one packed byte of all-on bits.
+	doc := Document{Commands: []Command{
+		{Type: "image", Params: []byte(`{"data":"gA==","widthDots":8,"heightDots":1}`)},
+	}}
+	data, err := doc.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	tail := data[len(data)-9:]
+	if tail[0] != 0x1D || tail[1] != 0x76 || tail[2] != 0x30 {
+		t.Fatalf("expected GS v 0 raster command, got % x", tail)
+	}
+	if tail[8] != 0x80 {
+		t.Fatalf("expected the decoded image byte 0x80, got %#x", tail[8])
+	}
+}
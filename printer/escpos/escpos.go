@@ -0,0 +1,314 @@
+// Package escpos provides a typed, fluent builder for composing ESC/POS
+// command streams server-side, so POS frontends submit structured print
+// documents instead of generating raw printer bytes themselves.
+package escpos
+
+import (
+	"bytes"
+	"fmt"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// Codepage selects the single- or multi-byte character set that Text()
+// transcodes into before appending it to the command stream.
+type Codepage int
+
+const (
+	CP437    Codepage = iota // PC437: USA, Standard Europe
+	CP850                    // PC850: Multilingual
+	GB18030                  // Simplified Chinese
+	ShiftJIS                 // Japanese
+	Big5                     // Traditional Chinese
+)
+
+// codepageNames maps the JSON/config codepage name to its Codepage
+// constant, used by both the document compiler and external callers that
+// only have a printer's configured codepage as a string.
+var codepageNames = map[string]Codepage{
+	"CP437":    CP437,
+	"CP850":    CP850,
+	"GB18030":  GB18030,
+	"ShiftJIS": ShiftJIS,
+	"Big5":     Big5,
+}
+
+// CodepageByName resolves a codepage name (e.g. "CP437") to its Codepage
+// constant.
+func CodepageByName(name string) (Codepage, bool) {
+	cp, ok := codepageNames[name]
+	return cp, ok
+}
+
+// escTTable holds the ESC t n selector value most Epson-compatible
+// firmware uses for single-byte codepages.
+var escTTable = map[Codepage]byte{
+	CP437: 0,
+	CP850: 2,
+}
+
+// encoding returns the x/text encoding used to transcode Text() input
+// from UTF-8 into this codepage.
+func (c Codepage) encoding() encoding.Encoding {
+	switch c {
+	case CP850:
+		return charmap.CodePage850
+	case GB18030:
+		return simplifiedchinese.GB18030
+	case ShiftJIS:
+		return japanese.ShiftJIS
+	case Big5:
+		return traditionalchinese.Big5
+	default:
+		return charmap.CodePage437
+	}
+}
+
+// Alignment selects text justification for Builder.Align.
+type Alignment byte
+
+const (
+	AlignLeft Alignment = iota
+	AlignCenter
+	AlignRight
+)
+
+// BarcodeType selects the symbology for Builder.Barcode.
+type BarcodeType byte
+
+const (
+	BarcodeUPCA BarcodeType = iota
+	BarcodeUPCE
+	BarcodeEAN13
+	BarcodeEAN8
+	BarcodeCODE39
+	BarcodeITF
+	BarcodeCODABAR
+	BarcodeCODE93
+	BarcodeCODE128
+)
+
+// barcodeGSk maps BarcodeType to the GS k m selector value (function B).
+var barcodeGSk = map[BarcodeType]byte{
+	BarcodeUPCA:    65,
+	BarcodeUPCE:    66,
+	BarcodeEAN13:   67,
+	BarcodeEAN8:    68,
+	BarcodeCODE39:  69,
+	BarcodeITF:     70,
+	BarcodeCODABAR: 71,
+	BarcodeCODE93:  72,
+	BarcodeCODE128: 73,
+}
+
+// barcodeTypeNames maps the JSON barcode type name used by the document
+// compiler to its BarcodeType constant.
+var barcodeTypeNames = map[string]BarcodeType{
+	"UPC-A":   BarcodeUPCA,
+	"UPC-E":   BarcodeUPCE,
+	"EAN13":   BarcodeEAN13,
+	"EAN8":    BarcodeEAN8,
+	"CODE39":  BarcodeCODE39,
+	"ITF":     BarcodeITF,
+	"CODABAR": BarcodeCODABAR,
+	"CODE93":  BarcodeCODE93,
+	"CODE128": BarcodeCODE128,
+}
+
+// Builder composes an ESC/POS byte stream via chained calls. It is not
+// safe for concurrent use; build one per print job.
+type Builder struct {
+	buf      bytes.Buffer
+	codepage Codepage
+	err      error
+}
+
+// NewBuilder returns a Builder that encodes Text() using codepage and
+// opens the stream with an ESC @ initialize followed by the matching
+// codepage select command.
+func NewBuilder(codepage Codepage) *Builder {
+	b := &Builder{codepage: codepage}
+	b.buf.Write([]byte{0x1B, 0x40}) // ESC @ : initialize printer
+	b.selectCodepage(codepage)
+	return b
+}
+
+func (b *Builder) selectCodepage(cp Codepage) {
+	if n, ok := escTTable[cp]; ok {
+		b.buf.Write([]byte{0x1B, 0x74, n}) // ESC t n
+		return
+	}
+	// Multi-byte codepages (GB18030/ShiftJIS/Big5) are selected by
+	// enabling the printer's Kanji/multi-byte mode rather than ESC t.
+	b.buf.Write([]byte{0x1C, 0x26}) // FS &
+}
+
+// Err returns the first error encountered while building, if any.
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// Text appends s, transcoded from UTF-8 into the Builder's codepage.
+func (b *Builder) Text(s string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	encoded, err := b.codepage.encoding().NewEncoder().String(s)
+	if err != nil {
+		b.err = fmt.Errorf("escpos: encode text: %w", err)
+		return b
+	}
+	b.buf.WriteString(encoded)
+	return b
+}
+
+// Bold toggles emphasized (bold) mode.
+func (b *Builder) Bold(on bool) *Builder {
+	b.buf.Write([]byte{0x1B, 0x45, boolByte(on)}) // ESC E n
+	return b
+}
+
+// Underline toggles underline mode.
+func (b *Builder) Underline(on bool) *Builder {
+	b.buf.Write([]byte{0x1B, 0x2D, boolByte(on)}) // ESC - n
+	return b
+}
+
+// Align sets text justification for subsequent Text() calls.
+func (b *Builder) Align(a Alignment) *Builder {
+	b.buf.Write([]byte{0x1B, 0x61, byte(a)}) // ESC a n
+	return b
+}
+
+// FontSize sets the character width/height multipliers, each clamped to
+// [1,8].
+func (b *Builder) FontSize(width, height int) *Builder {
+	width = clamp(width, 1, 8)
+	height = clamp(height, 1, 8)
+	n := byte((width-1)<<4 | (height - 1))
+	b.buf.Write([]byte{0x1D, 0x21, n}) // GS ! n
+	return b
+}
+
+// Barcode prints a 1D barcode of type t encoding data.
+func (b *Builder) Barcode(t BarcodeType, data string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	m, ok := barcodeGSk[t]
+	if !ok {
+		b.err = fmt.Errorf("escpos: unknown barcode type %d", t)
+		return b
+	}
+	if len(data) > 255 {
+		b.err = fmt.Errorf("escpos: barcode data too long (%d bytes)", len(data))
+		return b
+	}
+	b.buf.Write([]byte{0x1D, 0x6B, m, byte(len(data))}) // GS k m n
+	b.buf.WriteString(data)
+	return b
+}
+
+// QRCode prints a model 2 QR code encoding data at the given module size
+// (clamped to [1,16]) with error correction level M.
+func (b *Builder) QRCode(data string, moduleSize int) *Builder {
+	if b.err != nil {
+		return b
+	}
+	moduleSize = clamp(moduleSize, 1, 16)
+
+	b.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00}) // select model 2
+	b.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x43, byte(moduleSize)})
+	b.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x45, 0x31}) // error correction level M
+
+	store := len(data) + 3
+	pL := byte(store & 0xFF)
+	pH := byte((store >> 8) & 0xFF)
+	b.buf.Write([]byte{0x1D, 0x28, 0x6B, pL, pH, 0x31, 0x50, 0x30}) // store data
+	b.buf.WriteString(data)
+
+	b.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x51, 0x30}) // print symbol
+	return b
+}
+
+// Image appends GS v 0 raster bit image commands for a pre-packed 1-bpp
+// bitmap, MSB-first, widthDots wide and heightDots tall. Bands taller
+// than 255 dots are split automatically to stay within typical printer
+// buffer limits.
+func (b *Builder) Image(data []byte, widthDots, heightDots int) *Builder {
+	if b.err != nil {
+		return b
+	}
+	widthBytes := (widthDots + 7) / 8
+	if widthBytes == 0 || heightDots == 0 {
+		return b
+	}
+	if len(data) != widthBytes*heightDots {
+		b.err = fmt.Errorf("escpos: image data length %d does not match %dx%d bitmap", len(data), widthDots, heightDots)
+		return b
+	}
+
+	const maxBandHeight = 255
+	for y := 0; y < heightDots; y += maxBandHeight {
+		bandHeight := heightDots - y
+		if bandHeight > maxBandHeight {
+			bandHeight = maxBandHeight
+		}
+		b.writeRasterBand(data[y*widthBytes:(y+bandHeight)*widthBytes], widthBytes, bandHeight)
+	}
+	return b
+}
+
+func (b *Builder) writeRasterBand(data []byte, widthBytes, heightDots int) {
+	xL := byte(widthBytes & 0xFF)
+	xH := byte((widthBytes >> 8) & 0xFF)
+	yL := byte(heightDots & 0xFF)
+	yH := byte((heightDots >> 8) & 0xFF)
+	b.buf.Write([]byte{0x1D, 0x76, 0x30, 0x00, xL, xH, yL, yH}) // GS v 0 m xL xH yL yH
+	b.buf.Write(data)
+}
+
+// Cut feeds and performs a full paper cut.
+func (b *Builder) Cut() *Builder {
+	b.buf.Write([]byte{0x1D, 0x56, 0x00}) // GS V 0 : full cut
+	return b
+}
+
+// CashDrawer pulses the cash drawer kick-out connector (pin 2).
+func (b *Builder) CashDrawer() *Builder {
+	b.buf.Write([]byte{0x1B, 0x70, 0x00, 0x19, 0xFA}) // ESC p m t1 t2
+	return b
+}
+
+// FeedLines advances the paper by n lines, clamped to [0,255].
+func (b *Builder) FeedLines(n int) *Builder {
+	n = clamp(n, 0, 255)
+	b.buf.Write([]byte{0x1B, 0x64, byte(n)}) // ESC d n
+	return b
+}
+
+// Bytes returns the composed ESC/POS command stream.
+func (b *Builder) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func boolByte(on bool) byte {
+	if on {
+		return 1
+	}
+	return 0
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
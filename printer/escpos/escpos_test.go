@@ -0,0 +1,118 @@
+package escpos
+
+import "testing"
+
+func TestNewBuilderInitializesAndSelectsCodepage(t *testing.T) {
+	b := NewBuilder(CP850)
+	data := b.Bytes()
+	if len(data) < 5 || data[0] != 0x1B || data[1] != 0x40 {
+		t.Fatalf("expected an ESC @ initialize prefix, got % x", data)
+	}
+	if data[2] != 0x1B || data[3] != 0x74 || data[4] != 2 {
+		t.Fatalf("expected ESC t 2 for CP850, got % x", data[2:5])
+	}
+}
+
+func TestNewBuilderMultiByteCodepageUsesFSAmpersand(t *testing.T) {
+	b := NewBuilder(GB18030)
+	data := b.Bytes()
+	if len(data) < 4 || data[2] != 0x1C || data[3] != 0x26 {
+		t.Fatalf("expected FS & for a multi-byte codepage, got % x", data[2:4])
+	}
+}
+
+func TestTextEncodesIntoSelectedCodepage(t *testing.T) {
+	b := NewBuilder(CP437)
+	b.Text("AB")
+	if err := b.Err(); err != nil {
+		t.Fatalf("Text: %v", err)
+	}
+	data := b.Bytes()
+	if string(data[len(data)-2:]) != "AB" {
+		t.Fatalf("expected trailing ASCII bytes \"AB\", got % x", data[len(data)-2:])
+	}
+}
+
+func TestCutCashDrawerAndFeedLines(t *testing.T) {
+	b := NewBuilder(CP437)
+	start := len(b.Bytes())
+	b.Cut()
+	if got := b.Bytes()[start:]; len(got) != 3 || got[0] != 0x1D || got[1] != 0x56 || got[2] != 0x00 {
+		t.Fatalf("Cut: got % x, want GS V 0", got)
+	}
+
+	start = len(b.Bytes())
+	b.CashDrawer()
+	if got := b.Bytes()[start:]; len(got) != 5 || got[0] != 0x1B || got[1] != 0x70 {
+		t.Fatalf("CashDrawer: got % x, want ESC p ...", got)
+	}
+
+	start = len(b.Bytes())
+	b.FeedLines(300) // clamps to 255
+	if got := b.Bytes()[start:]; len(got) != 3 || got[2] != 255 {
+		t.Fatalf("FeedLines(300): got n=%d, want clamped to 255", got[2])
+	}
+}
+
+func TestBarcodeRejectsUnknownTypeAndOverlongData(t *testing.T) {
+	b := NewBuilder(CP437)
+	b.Barcode(BarcodeType(99), "123")
+	if b.Err() == nil {
+		t.Fatal("expected an error for an unknown barcode type")
+	}
+
+	b = NewBuilder(CP437)
+	b.Barcode(BarcodeCODE128, string(make([]byte, 256)))
+	if b.Err() == nil {
+		t.Fatal("expected an error for barcode data over 255 bytes")
+	}
+}
+
+func TestImageRejectsMismatchedDataLength(t *testing.T) {
+	b := NewBuilder(CP437)
+	b.Image([]byte{0x00, 0x00}, 16, 2) // needs 2 bytes/row * 2 rows = 4 bytes
+	if b.Err() == nil {
+		t.Fatal("expected an error when image data doesn't match widthDots x heightDots")
+	}
+}
+
+func TestImageSplitsTallBitmapsIntoBands(t *testing.T) {
+	const widthDots = 8
+	const heightDots = 300 // exceeds the 255-row band limit
+	data := make([]byte, (widthDots/8)*heightDots)
+
+	b := NewBuilder(CP437)
+	start := len(b.Bytes())
+	b.Image(data, widthDots, heightDots)
+	if err := b.Err(); err != nil {
+		t.Fatalf("Image: %v", err)
+	}
+
+	out := b.Bytes()[start:]
+	// Each band is an 8-byte GS v 0 header followed by its row data; a
+	// 300-row image must be split into a 255-row band and a 45-row band.
+	firstHeader := out[:8]
+	if firstHeader[0] != 0x1D || firstHeader[1] != 0x76 || firstHeader[2] != 0x30 {
+		t.Fatalf("expected GS v 0 header, got % x", firstHeader)
+	}
+	firstBandHeight := int(firstHeader[6]) | int(firstHeader[7])<<8
+	if firstBandHeight != 255 {
+		t.Fatalf("first band height = %d, want 255", firstBandHeight)
+	}
+
+	secondHeaderStart := 8 + 255*(widthDots/8)
+	secondHeader := out[secondHeaderStart : secondHeaderStart+8]
+	secondBandHeight := int(secondHeader[6]) | int(secondHeader[7])<<8
+	if secondBandHeight != 45 {
+		t.Fatalf("second band height = %d, want 45", secondBandHeight)
+	}
+}
+
+func TestCodepageByName(t *testing.T) {
+	if cp, ok := CodepageByName("GB18030"); !ok || cp != GB18030 {
+		t.Fatalf("CodepageByName(GB18030) = (%v, %v), want (GB18030, true)", cp, ok)
+	}
+	if _, ok := CodepageByName("nonexistent"); ok {
+		t.Fatal("expected ok=false for an unknown codepage name")
+	}
+}
@@ -0,0 +1,301 @@
+package printer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"printer-service/printer/escpos"
+)
+
+// DitherMode selects the algorithm used to convert a grayscale image into
+// a 1-bpp bitmap suitable for ESC/POS raster printing.
+type DitherMode int
+
+const (
+	DitherFloydSteinberg DitherMode = iota
+	DitherAtkinson
+	DitherThreshold
+)
+
+// ImageOptions controls how PrintImage rasterizes an image.Image before
+// sending it to the printer.
+type ImageOptions struct {
+	WidthDots int // target width in dots; rounded up to a multiple of 8
+	Dither    DitherMode
+	Threshold uint8 // used when Dither == DitherThreshold, 0-255
+	Rotation  int   // degrees, one of 0, 90, 180, 270
+	Codepage  escpos.Codepage
+}
+
+// DefaultImageOptions returns sane defaults for an 80mm thermal printer
+// (576 dots wide) using Floyd-Steinberg dithering.
+func DefaultImageOptions() ImageOptions {
+	return ImageOptions{
+		WidthDots: 576,
+		Dither:    DitherFloydSteinberg,
+		Threshold: 128,
+		Codepage:  escpos.CP437,
+	}
+}
+
+// PrintImage rasterizes img into ESC/POS GS v 0 raster commands and sends
+// it to printerName via the same path as other ESC/POS jobs.
+func PrintImage(printerName string, img image.Image, opts ImageOptions) error {
+	data, err := BuildImageEscPos(img, opts)
+	if err != nil {
+		return err
+	}
+	return PrintEscPos(printerName, string(data))
+}
+
+// BuildImageEscPos rasterizes img per opts and compiles it into the ESC/POS
+// GS v 0 raster command bytes, without sending them anywhere. Callers that
+// need to queue or persist the job (rather than print immediately) build
+// the bytes this way and dispatch them like any other ESC/POS job.
+func BuildImageEscPos(img image.Image, opts ImageOptions) ([]byte, error) {
+	data, widthDots, heightDots, err := rasterize(img, opts)
+	if err != nil {
+		return nil, fmt.Errorf("rasterize image: %w", err)
+	}
+
+	b := escpos.NewBuilder(opts.Codepage).Image(data, widthDots, heightDots)
+	if err := b.Err(); err != nil {
+		return nil, fmt.Errorf("build raster command: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// rasterize converts img into a 1-bpp, MSB-first packed bitmap at the
+// requested width (rounded up to a multiple of 8 dots), downscaling to
+// preserve aspect ratio, then applies rotation, grayscale conversion and
+// the configured dithering algorithm.
+func rasterize(img image.Image, opts ImageOptions) (data []byte, widthDots, heightDots int, err error) {
+	if opts.Rotation != 0 {
+		img = rotateImage(img, opts.Rotation)
+	}
+
+	widthDots = opts.WidthDots
+	if widthDots <= 0 {
+		widthDots = img.Bounds().Dx()
+	}
+	widthDots = (widthDots + 7) / 8 * 8
+
+	gray := toGrayScaled(img, widthDots)
+	heightDots = gray.Bounds().Dy()
+
+	var bits [][]bool
+	switch opts.Dither {
+	case DitherAtkinson:
+		bits = ditherAtkinson(gray)
+	case DitherThreshold:
+		threshold := opts.Threshold
+		if threshold == 0 {
+			threshold = 128
+		}
+		bits = ditherThreshold(gray, threshold)
+	default:
+		bits = ditherFloydSteinberg(gray)
+	}
+
+	data = packBits(bits, widthDots)
+	return data, widthDots, heightDots, nil
+}
+
+// rotateImage rotates img clockwise by degrees, which must be one of 0,
+// 90, 180 or 270; any other value is treated as 0 (no rotation).
+func rotateImage(img image.Image, degrees int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	switch ((degrees % 360) + 360) % 360 {
+	case 90:
+		out := image.NewRGBA(image.Rect(0, 0, h, w))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				out.Set(h-1-y, x, img.At(b.Min.X+x, b.Min.Y+y))
+			}
+		}
+		return out
+	case 180:
+		out := image.NewRGBA(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				out.Set(w-1-x, h-1-y, img.At(b.Min.X+x, b.Min.Y+y))
+			}
+		}
+		return out
+	case 270:
+		out := image.NewRGBA(image.Rect(0, 0, h, w))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				out.Set(y, w-1-x, img.At(b.Min.X+x, b.Min.Y+y))
+			}
+		}
+		return out
+	default:
+		return img
+	}
+}
+
+// toGrayScaled downscales img to targetWidth dots wide, preserving aspect
+// ratio, by averaging each destination pixel's source block, then
+// converts the result to 8-bit grayscale.
+func toGrayScaled(img image.Image, targetWidth int) *image.Gray {
+	b := img.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	if srcW == 0 || srcH == 0 || targetWidth == 0 {
+		return image.NewGray(image.Rect(0, 0, targetWidth, 0))
+	}
+
+	targetHeight := int(math.Round(float64(srcH) * float64(targetWidth) / float64(srcW)))
+	if targetHeight < 1 {
+		targetHeight = 1
+	}
+
+	out := image.NewGray(image.Rect(0, 0, targetWidth, targetHeight))
+	for y := 0; y < targetHeight; y++ {
+		sy0 := y * srcH / targetHeight
+		sy1 := (y + 1) * srcH / targetHeight
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < targetWidth; x++ {
+			sx0 := x * srcW / targetWidth
+			sx1 := (x + 1) * srcW / targetWidth
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			var sum, count int
+			for sy := sy0; sy < sy1 && sy < srcH; sy++ {
+				for sx := sx0; sx < sx1 && sx < srcW; sx++ {
+					g := color.GrayModel.Convert(img.At(b.Min.X+sx, b.Min.Y+sy)).(color.Gray)
+					sum += int(g.Y)
+					count++
+				}
+			}
+			if count == 0 {
+				count = 1
+			}
+			out.SetGray(x, y, color.Gray{Y: uint8(sum / count)})
+		}
+	}
+	return out
+}
+
+// ditherFloydSteinberg applies classic Floyd-Steinberg error diffusion,
+// returning true where a dot should be printed (dark).
+func ditherFloydSteinberg(gray *image.Gray) [][]bool {
+	w, h := gray.Bounds().Dx(), gray.Bounds().Dy()
+	levels := grayLevels(gray)
+	bits := make([][]bool, h)
+
+	for y := 0; y < h; y++ {
+		bits[y] = make([]bool, w)
+		for x := 0; x < w; x++ {
+			old := levels[y][x]
+			black := old < 128
+			bits[y][x] = black
+
+			newVal := 255.0
+			if black {
+				newVal = 0
+			}
+			errv := old - newVal
+			diffuse(levels, w, h, x+1, y, errv*7/16)
+			diffuse(levels, w, h, x-1, y+1, errv*3/16)
+			diffuse(levels, w, h, x, y+1, errv*5/16)
+			diffuse(levels, w, h, x+1, y+1, errv*1/16)
+		}
+	}
+	return bits
+}
+
+// ditherAtkinson applies Atkinson dithering, which diffuses only 3/4 of
+// the quantization error and tends to preserve contrast better than
+// Floyd-Steinberg on receipt-sized images.
+func ditherAtkinson(gray *image.Gray) [][]bool {
+	w, h := gray.Bounds().Dx(), gray.Bounds().Dy()
+	levels := grayLevels(gray)
+	bits := make([][]bool, h)
+
+	for y := 0; y < h; y++ {
+		bits[y] = make([]bool, w)
+		for x := 0; x < w; x++ {
+			old := levels[y][x]
+			black := old < 128
+			bits[y][x] = black
+
+			newVal := 255.0
+			if black {
+				newVal = 0
+			}
+			errv := (old - newVal) / 8
+			diffuse(levels, w, h, x+1, y, errv)
+			diffuse(levels, w, h, x+2, y, errv)
+			diffuse(levels, w, h, x-1, y+1, errv)
+			diffuse(levels, w, h, x, y+1, errv)
+			diffuse(levels, w, h, x+1, y+1, errv)
+			diffuse(levels, w, h, x, y+2, errv)
+		}
+	}
+	return bits
+}
+
+// ditherThreshold applies simple fixed-threshold black/white quantization.
+func ditherThreshold(gray *image.Gray, threshold uint8) [][]bool {
+	w, h := gray.Bounds().Dx(), gray.Bounds().Dy()
+	bits := make([][]bool, h)
+	for y := 0; y < h; y++ {
+		bits[y] = make([]bool, w)
+		for x := 0; x < w; x++ {
+			bits[y][x] = gray.GrayAt(x, y).Y < threshold
+		}
+	}
+	return bits
+}
+
+func grayLevels(gray *image.Gray) [][]float64 {
+	w, h := gray.Bounds().Dx(), gray.Bounds().Dy()
+	levels := make([][]float64, h)
+	for y := 0; y < h; y++ {
+		levels[y] = make([]float64, w)
+		for x := 0; x < w; x++ {
+			levels[y][x] = float64(gray.GrayAt(x, y).Y)
+		}
+	}
+	return levels
+}
+
+func diffuse(levels [][]float64, w, h, x, y int, amount float64) {
+	if x < 0 || x >= w || y < 0 || y >= h {
+		return
+	}
+	v := levels[y][x] + amount
+	if v < 0 {
+		v = 0
+	}
+	if v > 255 {
+		v = 255
+	}
+	levels[y][x] = v
+}
+
+// packBits packs a row-major bit matrix into MSB-first bytes, widthBytes
+// per row, as required by GS v 0.
+func packBits(bits [][]bool, widthDots int) []byte {
+	widthBytes := (widthDots + 7) / 8
+	data := make([]byte, widthBytes*len(bits))
+	for y, row := range bits {
+		for x, on := range row {
+			if !on {
+				continue
+			}
+			data[y*widthBytes+x/8] |= 0x80 >> uint(x%8)
+		}
+	}
+	return data
+}
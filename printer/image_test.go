@@ -0,0 +1,132 @@
+package printer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func solidGray(w, h int, v uint8) *image.Gray {
+	g := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			g.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return g
+}
+
+func TestDitherThresholdSplitsOnThreshold(t *testing.T) {
+	g := solidGray(4, 1, 100)
+	bits := ditherThreshold(g, 128)
+	for x, on := range bits[0] {
+		if !on {
+			t.Fatalf("pixel %d: value 100 under threshold 128 should be on (dark)", x)
+		}
+	}
+
+	g = solidGray(4, 1, 200)
+	bits = ditherThreshold(g, 128)
+	for x, on := range bits[0] {
+		if on {
+			t.Fatalf("pixel %d: value 200 over threshold 128 should be off (light)", x)
+		}
+	}
+}
+
+func TestDitherFloydSteinbergAllBlackIsAllOn(t *testing.T) {
+	g := solidGray(8, 8, 0)
+	bits := ditherFloydSteinberg(g)
+	for y, row := range bits {
+		for x, on := range row {
+			if !on {
+				t.Fatalf("pixel (%d,%d) of an all-black image should be on", x, y)
+			}
+		}
+	}
+}
+
+func TestDitherFloydSteinbergAllWhiteIsAllOff(t *testing.T) {
+	g := solidGray(8, 8, 255)
+	bits := ditherFloydSteinberg(g)
+	for y, row := range bits {
+		for x, on := range row {
+			if on {
+				t.Fatalf("pixel (%d,%d) of an all-white image should be off", x, y)
+			}
+		}
+	}
+}
+
+func TestDitherAtkinsonMidGrayProducesAMix(t *testing.T) {
+	g := solidGray(16, 16, 128)
+	bits := ditherAtkinson(g)
+	var on, off int
+	for _, row := range bits {
+		for _, b := range row {
+			if b {
+				on++
+			} else {
+				off++
+			}
+		}
+	}
+	if on == 0 || off == 0 {
+		t.Fatalf("mid-gray input should dither into a mix of on/off pixels, got on=%d off=%d", on, off)
+	}
+}
+
+func TestPackBitsMSBFirst(t *testing.T) {
+	bits := [][]bool{{true, false, true, false, false, false, false, false, true}}
+	data := packBits(bits, 9) // 9 dots -> 2 bytes wide
+	if len(data) != 2 {
+		t.Fatalf("len(data) = %d, want 2", len(data))
+	}
+	if data[0] != 0xA0 { // 1010 0000
+		t.Fatalf("data[0] = %#x, want 0xa0", data[0])
+	}
+	if data[1] != 0x80 { // 1 followed by 7 zero bits
+		t.Fatalf("data[1] = %#x, want 0x80", data[1])
+	}
+}
+
+func TestRasterizeRoundsWidthUpToMultipleOf8(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 10, 10))
+	data, widthDots, heightDots, err := rasterize(img, ImageOptions{WidthDots: 10, Dither: DitherThreshold, Threshold: 128})
+	if err != nil {
+		t.Fatalf("rasterize: %v", err)
+	}
+	if widthDots != 16 {
+		t.Fatalf("widthDots = %d, want 16 (10 rounded up to a multiple of 8)", widthDots)
+	}
+	if len(data) != (widthDots/8)*heightDots {
+		t.Fatalf("len(data) = %d, want %d", len(data), (widthDots/8)*heightDots)
+	}
+}
+
+func TestRotateImage90PreservesDimensionsSwapped(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 6, 4))
+	rotated := rotateImage(img, 90)
+	b := rotated.Bounds()
+	if b.Dx() != 4 || b.Dy() != 6 {
+		t.Fatalf("rotated bounds = %dx%d, want 4x6", b.Dx(), b.Dy())
+	}
+}
+
+func TestRotateImageUnknownDegreesIsNoop(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 6, 4))
+	rotated := rotateImage(img, 45)
+	if rotated != image.Image(img) {
+		t.Fatal("an unsupported rotation should return the image unchanged")
+	}
+}
+
+func TestDefaultImageOptions(t *testing.T) {
+	opts := DefaultImageOptions()
+	if opts.WidthDots != 576 {
+		t.Fatalf("WidthDots = %d, want 576", opts.WidthDots)
+	}
+	if opts.Dither != DitherFloydSteinberg {
+		t.Fatalf("Dither = %v, want DitherFloydSteinberg", opts.Dither)
+	}
+}
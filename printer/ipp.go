@@ -0,0 +1,302 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Minimal IPP (RFC 8010/8011) client covering the three operations this
+// service needs: Print-Job, Get-Printer-Attributes and Get-Jobs. No
+// authentication beyond what the transport (https for ipps://) provides.
+const (
+	ippMajorVersion = 0x02
+	ippMinorVersion = 0x00
+
+	ippOpPrintJob             = 0x0002
+	ippOpGetJobs              = 0x000A
+	ippOpGetPrinterAttributes = 0x000B
+
+	ippTagOperationAttributes = 0x01
+	ippTagJobAttributes       = 0x02
+	ippTagEndOfAttributes     = 0x03
+	ippTagPrinterAttributes   = 0x04
+
+	ippTagInteger         = 0x21
+	ippTagBoolean         = 0x22
+	ippTagEnum            = 0x23
+	ippTagCharset         = 0x47
+	ippTagNaturalLanguage = 0x48
+	ippTagURI             = 0x45
+	ippTagNameWithoutLang = 0x42
+	ippTagMimeMediaType   = 0x49
+	ippTagKeyword         = 0x44
+)
+
+// defaultPrinterAttributes is requested when GetPrinterAttributes isn't
+// given an explicit attribute list.
+var defaultPrinterAttributes = []string{
+	"printer-name",
+	"printer-state",
+	"printer-state-reasons",
+	"printer-is-accepting-jobs",
+}
+
+// defaultJobAttributes is requested when GetJobs isn't given an explicit
+// attribute list.
+var defaultJobAttributes = []string{
+	"job-id",
+	"job-name",
+	"job-state",
+	"job-state-reasons",
+}
+
+// PrintIPP submits data as a print job to an IPP (or IPPS) printer
+// identified by uri, using a single Print-Job request.
+func PrintIPP(uri string, data []byte) error {
+	var attrs bytes.Buffer
+	writeIPPAttribute(&attrs, ippTagMimeMediaType, "document-format", "application/octet-stream")
+
+	body, err := doIPPRequest(uri, ippOpPrintJob, attrs.Bytes(), data)
+	if err != nil {
+		return err
+	}
+
+	statusCode, _, err := parseIPPResponse(body)
+	if err != nil {
+		return fmt.Errorf("parse ipp response: %w", err)
+	}
+	if statusCode >= 0x0100 {
+		return fmt.Errorf("ipp printer %s rejected job (status 0x%04x)", uri, statusCode)
+	}
+	return nil
+}
+
+// GetPrinterAttributes queries uri via IPP Get-Printer-Attributes and
+// returns the requested printer attributes as name -> values. With no
+// names given, a small default set covering printer state is requested.
+func GetPrinterAttributes(uri string, names ...string) (map[string][]string, error) {
+	if len(names) == 0 {
+		names = defaultPrinterAttributes
+	}
+
+	var attrs bytes.Buffer
+	writeIPPKeywordList(&attrs, "requested-attributes", names)
+
+	body, err := doIPPRequest(uri, ippOpGetPrinterAttributes, attrs.Bytes(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	statusCode, groups, err := parseIPPResponse(body)
+	if err != nil {
+		return nil, fmt.Errorf("parse ipp response: %w", err)
+	}
+	if statusCode >= 0x0100 {
+		return nil, fmt.Errorf("ipp printer %s rejected get-printer-attributes (status 0x%04x)", uri, statusCode)
+	}
+	return firstGroup(groups, ippTagPrinterAttributes), nil
+}
+
+// GetJobs queries uri via IPP Get-Jobs and returns one attribute map per
+// job (name -> values), in the order the printer reported them.
+func GetJobs(uri string, names ...string) ([]map[string][]string, error) {
+	if len(names) == 0 {
+		names = defaultJobAttributes
+	}
+
+	var attrs bytes.Buffer
+	writeIPPKeywordList(&attrs, "requested-attributes", names)
+
+	body, err := doIPPRequest(uri, ippOpGetJobs, attrs.Bytes(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	statusCode, groups, err := parseIPPResponse(body)
+	if err != nil {
+		return nil, fmt.Errorf("parse ipp response: %w", err)
+	}
+	if statusCode >= 0x0100 {
+		return nil, fmt.Errorf("ipp printer %s rejected get-jobs (status 0x%04x)", uri, statusCode)
+	}
+
+	var jobs []map[string][]string
+	for _, g := range groups {
+		if g.tag == ippTagJobAttributes {
+			jobs = append(jobs, g.attrs)
+		}
+	}
+	return jobs, nil
+}
+
+// doIPPRequest encodes an IPP request for op against printerURI, with
+// extraOperationAttrs appended after the standard charset/language/
+// printer-uri/requesting-user-name attributes, followed by trailing data
+// (the document body, for Print-Job), and posts it over HTTP(S).
+func doIPPRequest(printerURI string, op uint16, extraOperationAttrs []byte, data []byte) ([]byte, error) {
+	httpURL := strings.Replace(printerURI, "ipps://", "https://", 1)
+	httpURL = strings.Replace(httpURL, "ipp://", "http://", 1)
+
+	req := buildIPPRequest(op, printerURI, extraOperationAttrs, data)
+
+	resp, err := http.Post(httpURL, "application/ipp", bytes.NewReader(req))
+	if err != nil {
+		return nil, fmt.Errorf("ipp request to %s: %w", printerURI, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read ipp response: %w", err)
+	}
+	return body, nil
+}
+
+// buildIPPRequest encodes an IPP request: header, operation-attributes
+// group (charset, language, printer-uri, requesting-user-name, plus
+// extraOperationAttrs), end-of-attributes, then any trailing document
+// data.
+func buildIPPRequest(op uint16, printerURI string, extraOperationAttrs []byte, data []byte) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteByte(ippMajorVersion)
+	buf.WriteByte(ippMinorVersion)
+	binary.Write(&buf, binary.BigEndian, op)
+	binary.Write(&buf, binary.BigEndian, uint32(1)) // request-id
+
+	buf.WriteByte(ippTagOperationAttributes)
+	writeIPPAttribute(&buf, ippTagCharset, "attributes-charset", "utf-8")
+	writeIPPAttribute(&buf, ippTagNaturalLanguage, "attributes-natural-language", "en")
+	writeIPPAttribute(&buf, ippTagURI, "printer-uri", printerURI)
+	writeIPPAttribute(&buf, ippTagNameWithoutLang, "requesting-user-name", "printer-service")
+	buf.Write(extraOperationAttrs)
+
+	buf.WriteByte(ippTagEndOfAttributes)
+	buf.Write(data)
+
+	return buf.Bytes()
+}
+
+func writeIPPAttribute(buf *bytes.Buffer, tag byte, name, value string) {
+	buf.WriteByte(tag)
+	binary.Write(buf, binary.BigEndian, uint16(len(name)))
+	buf.WriteString(name)
+	binary.Write(buf, binary.BigEndian, uint16(len(value)))
+	buf.WriteString(value)
+}
+
+// writeIPPKeywordList writes a 1setOf keyword attribute: the first value
+// carries name, every subsequent value repeats the tag with an empty
+// name, per the IPP multi-valued attribute encoding.
+func writeIPPKeywordList(buf *bytes.Buffer, name string, values []string) {
+	for i, v := range values {
+		if i == 0 {
+			writeIPPAttribute(buf, ippTagKeyword, name, v)
+		} else {
+			writeIPPAttribute(buf, ippTagKeyword, "", v)
+		}
+	}
+}
+
+// ippGroup is one attribute-group from an IPP response (operation,
+// printer or job attributes), decoded into name -> values.
+type ippGroup struct {
+	tag   byte
+	attrs map[string][]string
+}
+
+// parseIPPResponse decodes an IPP response: the status-code header field
+// and every attribute group up to end-of-attributes.
+func parseIPPResponse(body []byte) (statusCode uint16, groups []ippGroup, err error) {
+	if len(body) < 8 {
+		return 0, nil, fmt.Errorf("response too short (%d bytes)", len(body))
+	}
+	statusCode = binary.BigEndian.Uint16(body[2:4])
+
+	pos := 8 // version(2) + status-code(2) + request-id(4)
+	var cur *ippGroup
+	lastName := ""
+
+	for pos < len(body) {
+		tag := body[pos]
+		pos++
+
+		if tag == ippTagEndOfAttributes {
+			break
+		}
+		if tag <= 0x0F {
+			// begin-attribute-group-tag: operation/job/printer/... attributes
+			if cur != nil {
+				groups = append(groups, *cur)
+			}
+			cur = &ippGroup{tag: tag, attrs: make(map[string][]string)}
+			lastName = ""
+			continue
+		}
+
+		if cur == nil {
+			return 0, nil, fmt.Errorf("attribute value outside any group")
+		}
+
+		name, value, n, err := parseIPPAttribute(body[pos:])
+		if err != nil {
+			return 0, nil, err
+		}
+		pos += n
+
+		if name == "" {
+			name = lastName // additional value of a multi-valued attribute
+		} else {
+			lastName = name
+		}
+		cur.attrs[name] = append(cur.attrs[name], value)
+	}
+
+	if cur != nil {
+		groups = append(groups, *cur)
+	}
+	return statusCode, groups, nil
+}
+
+// parseIPPAttribute decodes a single name/value pair (the tag byte must
+// already be consumed by the caller) and returns how many bytes it read.
+func parseIPPAttribute(b []byte) (name, value string, n int, err error) {
+	if len(b) < 2 {
+		return "", "", 0, fmt.Errorf("truncated attribute name length")
+	}
+	nameLen := int(binary.BigEndian.Uint16(b[0:2]))
+	n = 2
+	if len(b) < n+nameLen {
+		return "", "", 0, fmt.Errorf("truncated attribute name")
+	}
+	name = string(b[n : n+nameLen])
+	n += nameLen
+
+	if len(b) < n+2 {
+		return "", "", 0, fmt.Errorf("truncated attribute value length")
+	}
+	valueLen := int(binary.BigEndian.Uint16(b[n : n+2]))
+	n += 2
+	if len(b) < n+valueLen {
+		return "", "", 0, fmt.Errorf("truncated attribute value")
+	}
+	value = string(b[n : n+valueLen])
+	n += valueLen
+
+	return name, value, n, nil
+}
+
+// firstGroup returns the attrs of the first group in groups with the
+// given tag, or nil if none matched.
+func firstGroup(groups []ippGroup, tag byte) map[string][]string {
+	for _, g := range groups {
+		if g.tag == tag {
+			return g.attrs
+		}
+	}
+	return nil
+}
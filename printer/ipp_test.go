@@ -0,0 +1,112 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestBuildIPPRequestEncodesHeaderAndOperationAttributes(t *testing.T) {
+	req := buildIPPRequest(ippOpPrintJob, "ipp://printer.local/ipp/print", nil, []byte("document body"))
+
+	if req[0] != ippMajorVersion || req[1] != ippMinorVersion {
+		t.Fatalf("version = % x, want %x %x", req[:2], ippMajorVersion, ippMinorVersion)
+	}
+	if op := binary.BigEndian.Uint16(req[2:4]); op != ippOpPrintJob {
+		t.Fatalf("operation-id = %#x, want %#x", op, ippOpPrintJob)
+	}
+	if !bytes.HasSuffix(req, []byte("document body")) {
+		t.Fatal("expected the document body to be appended after end-of-attributes")
+	}
+	if req[8] != ippTagOperationAttributes {
+		t.Fatalf("first group tag = %#x, want operation-attributes (%#x)", req[8], ippTagOperationAttributes)
+	}
+}
+
+func TestWriteAndParseIPPAttributeRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	writeIPPAttribute(&buf, ippTagURI, "printer-uri", "ipp://printer.local/ipp/print")
+
+	// parseIPPAttribute expects the tag byte already consumed by the
+	// caller, as parseIPPResponse does.
+	body := buf.Bytes()[1:]
+	name, value, n, err := parseIPPAttribute(body)
+	if err != nil {
+		t.Fatalf("parseIPPAttribute: %v", err)
+	}
+	if name != "printer-uri" || value != "ipp://printer.local/ipp/print" {
+		t.Fatalf("got (%q, %q), want (printer-uri, ipp://printer.local/ipp/print)", name, value)
+	}
+	if n != len(body) {
+		t.Fatalf("n = %d, want %d (consumed the whole attribute)", n, len(body))
+	}
+}
+
+func TestWriteIPPKeywordListEncodesMultiValue(t *testing.T) {
+	var buf bytes.Buffer
+	writeIPPKeywordList(&buf, "requested-attributes", []string{"printer-name", "printer-state"})
+
+	_, groups, err := parseIPPResponseBody(t, buf.Bytes())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	values := groups[ippTagOperationAttributes]["requested-attributes"]
+	if len(values) != 2 || values[0] != "printer-name" || values[1] != "printer-state" {
+		t.Fatalf("requested-attributes = %v, want [printer-name printer-state]", values)
+	}
+}
+
+// parseIPPResponseBody wraps buf (a raw operation-attributes-group
+// payload, as writeIPPKeywordList produces) in a minimal response header
+// plus group/end-of-attributes framing so parseIPPResponse can decode it.
+func parseIPPResponseBody(t *testing.T, attrs []byte) (uint16, map[byte]map[string][]string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.Write([]byte{ippMajorVersion, ippMinorVersion})
+	binary.Write(&buf, binary.BigEndian, uint16(0))
+	binary.Write(&buf, binary.BigEndian, uint32(1))
+	buf.WriteByte(ippTagOperationAttributes)
+	buf.Write(attrs)
+	buf.WriteByte(ippTagEndOfAttributes)
+
+	status, groups, err := parseIPPResponse(buf.Bytes())
+	byTag := make(map[byte]map[string][]string)
+	for _, g := range groups {
+		byTag[g.tag] = g.attrs
+	}
+	return status, byTag, err
+}
+
+func TestParseIPPResponseDecodesMultipleGroups(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write([]byte{ippMajorVersion, ippMinorVersion})
+	binary.Write(&buf, binary.BigEndian, uint16(0x0000)) // successful-ok
+	binary.Write(&buf, binary.BigEndian, uint32(1))
+
+	buf.WriteByte(ippTagOperationAttributes)
+	writeIPPAttribute(&buf, ippTagCharset, "attributes-charset", "utf-8")
+
+	buf.WriteByte(ippTagPrinterAttributes)
+	writeIPPAttribute(&buf, ippTagKeyword, "printer-state", "idle")
+
+	buf.WriteByte(ippTagEndOfAttributes)
+
+	statusCode, groups, err := parseIPPResponse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("parseIPPResponse: %v", err)
+	}
+	if statusCode != 0 {
+		t.Fatalf("statusCode = %#x, want 0", statusCode)
+	}
+
+	printerAttrs := firstGroup(groups, ippTagPrinterAttributes)
+	if printerAttrs["printer-state"][0] != "idle" {
+		t.Fatalf("printer-state = %v, want [idle]", printerAttrs["printer-state"])
+	}
+}
+
+func TestParseIPPResponseTruncatedBody(t *testing.T) {
+	if _, _, err := parseIPPResponse([]byte{0x02, 0x00}); err == nil {
+		t.Fatal("expected an error for a response shorter than the fixed header")
+	}
+}
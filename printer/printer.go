@@ -189,8 +189,9 @@ func printUnix(printerName string, content string) error {
 	return nil
 }
 
-// PrintEscPos sends ESC/POS commands to specified printer
-func PrintEscPos(printerName string, escPosData string) error {
+// printEscPosSpooler sends ESC/POS commands to printerName via the host
+// OS's print spooler.
+func printEscPosSpooler(printerName string, escPosData string) error {
 	switch runtime.GOOS {
 	case "windows":
 		return printEscPosWindows(printerName, escPosData)
@@ -201,6 +202,15 @@ func PrintEscPos(printerName string, escPosData string) error {
 	}
 }
 
+// PrintEscPos sends ESC/POS commands to the printer identified by
+// printerName, which may be a bare OS spooler name (the default) or one
+// of the transport URIs understood by Dispatch (spooler://, raw://,
+// ipp://, ipps://) for network-attached printers with no OS spooler
+// entry.
+func PrintEscPos(printerName string, escPosData string) error {
+	return Dispatch(printerName, []byte(escPosData))
+}
+
 func printEscPosWindows(printerName string, escPosData string) error {
 	// Create temporary file with ESC/POS data
 	tempFile := "print_escpos_temp.bin"
@@ -0,0 +1,32 @@
+// Package queue implements a persistent, per-printer FIFO print job
+// queue with retries and cancellation, replacing the fire-and-forget
+// print calls the websocket layer used to make directly.
+package queue
+
+import "time"
+
+// JobState is the lifecycle stage of a queued print job.
+type JobState string
+
+const (
+	StateQueued    JobState = "queued"
+	StatePrinting  JobState = "printing"
+	StateDone      JobState = "done"
+	StateFailed    JobState = "failed"
+	StateCancelled JobState = "cancelled"
+)
+
+// Job is a single print request tracked by the queue, from submission
+// through its terminal state. ID doubles as an idempotency key: a client
+// may resubmit the same ID safely and get the original job back.
+type Job struct {
+	ID          string    `json:"id"`
+	PrinterName string    `json:"printerName"`
+	Format      string    `json:"format"` // "text", "escpos"
+	Payload     []byte    `json:"payload"`
+	State       JobState  `json:"state"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"lastError,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,272 @@
+package queue
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"printer-service/printer"
+)
+
+// RetryPolicy controls how a failed job is retried before being marked
+// StateFailed.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+}
+
+// DefaultRetryPolicy retries a transient failure 3 times with exponential
+// backoff starting at one second.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Second}
+}
+
+// backoffFor returns the delay before retrying a job that has failed
+// attempts times, doubling from BaseBackoff each attempt.
+func (p RetryPolicy) backoffFor(attempts int) time.Duration {
+	return p.BaseBackoff * time.Duration(1<<uint(attempts-1))
+}
+
+// terminalRetention is how long a job stays queryable via Get/List after
+// reaching a terminal state before it's pruned from the store and the
+// in-memory index. Without this, done/failed/cancelled jobs accumulate
+// in the BoltDB file (and get reloaded on every restart) forever.
+const terminalRetention = 24 * time.Hour
+
+const pruneInterval = time.Hour
+
+// Manager runs one FIFO worker per printer, persists job state via a
+// Store, and notifies OnStateChange as jobs progress.
+type Manager struct {
+	store  Store
+	policy RetryPolicy
+
+	mu      sync.Mutex
+	jobs    map[string]Job
+	workers map[string]chan Job
+
+	// OnStateChange, if set, is called (not necessarily from the
+	// caller's goroutine) whenever a job transitions state.
+	OnStateChange func(Job)
+}
+
+// NewManager creates a Manager backed by store, re-queuing any job left
+// in StateQueued or StatePrinting from a previous run.
+func NewManager(store Store, policy RetryPolicy) (*Manager, error) {
+	m := &Manager{
+		store:   store,
+		policy:  policy,
+		jobs:    make(map[string]Job),
+		workers: make(map[string]chan Job),
+	}
+
+	existing, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load persisted jobs: %w", err)
+	}
+
+	for _, job := range existing {
+		m.jobs[job.ID] = job
+		if job.State == StateQueued || job.State == StatePrinting {
+			job.State = StateQueued
+			m.enqueue(job)
+		}
+	}
+
+	go m.pruneLoop()
+
+	return m, nil
+}
+
+// pruneLoop periodically removes jobs that have sat in a terminal state
+// longer than terminalRetention, for the lifetime of the process.
+func (m *Manager) pruneLoop() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.prune(terminalRetention)
+	}
+}
+
+// prune deletes terminal jobs whose last update is older than olderThan
+// from both the in-memory index and the store.
+func (m *Manager) prune(olderThan time.Duration) {
+	cutoff := time.Now().Add(-olderThan)
+
+	m.mu.Lock()
+	var stale []string
+	for id, job := range m.jobs {
+		if isTerminal(job.State) && job.UpdatedAt.Before(cutoff) {
+			stale = append(stale, id)
+		}
+	}
+	for _, id := range stale {
+		delete(m.jobs, id)
+	}
+	m.mu.Unlock()
+
+	for _, id := range stale {
+		if err := m.store.Delete(id); err != nil {
+			fmt.Printf("queue: failed to delete pruned job %s: %v\n", id, err)
+		}
+	}
+}
+
+func isTerminal(state JobState) bool {
+	return state == StateDone || state == StateFailed || state == StateCancelled
+}
+
+// Submit enqueues job for printing. If job.ID matches an already known
+// job, the existing job is returned unchanged (idempotent resubmission).
+func (m *Manager) Submit(job Job) (Job, error) {
+	m.mu.Lock()
+	if existing, ok := m.jobs[job.ID]; ok {
+		m.mu.Unlock()
+		return existing, nil
+	}
+
+	now := time.Now()
+	job.State = StateQueued
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	if err := m.store.Save(job); err != nil {
+		// Don't leave a zombie queued-forever entry keyed by the
+		// client's idempotency key: without this, a retried submit of
+		// the same jobID would keep finding this entry above and
+		// silently swallow the original persistence failure.
+		m.mu.Lock()
+		delete(m.jobs, job.ID)
+		m.mu.Unlock()
+		return Job{}, fmt.Errorf("persist job %s: %w", job.ID, err)
+	}
+
+	m.enqueue(job)
+	return job, nil
+}
+
+// Get returns a known job by ID.
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+// List returns a snapshot of all known jobs.
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// Cancel marks a queued job as cancelled. A job already printing runs to
+// completion, since bytes already written to the spooler or wire can't
+// be recalled.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("unknown job %s", id)
+	}
+	if job.State != StateQueued {
+		m.mu.Unlock()
+		return fmt.Errorf("job %s is %s, not queued", id, job.State)
+	}
+	job.State = StateCancelled
+	job.UpdatedAt = time.Now()
+	m.jobs[id] = job
+	m.mu.Unlock()
+
+	m.persistAndNotify(job)
+	return nil
+}
+
+func (m *Manager) enqueue(job Job) {
+	m.mu.Lock()
+	ch, ok := m.workers[job.PrinterName]
+	if !ok {
+		ch = make(chan Job, 64)
+		m.workers[job.PrinterName] = ch
+		go m.runWorker(ch)
+	}
+	m.mu.Unlock()
+
+	ch <- job
+}
+
+// runWorker processes jobs for a single printer in submission order, so
+// two jobs for the same printer never race each other.
+func (m *Manager) runWorker(jobs chan Job) {
+	for job := range jobs {
+		m.process(job)
+	}
+}
+
+func (m *Manager) process(job Job) {
+	m.mu.Lock()
+	current := m.jobs[job.ID]
+	m.mu.Unlock()
+	if current.State == StateCancelled {
+		return
+	}
+
+	current.State = StatePrinting
+	current.UpdatedAt = time.Now()
+	m.persistAndNotify(current)
+
+	err := m.print(current)
+	current.Attempts++
+
+	if err == nil {
+		current.State = StateDone
+		current.LastError = ""
+		current.UpdatedAt = time.Now()
+		m.persistAndNotify(current)
+		return
+	}
+
+	current.LastError = err.Error()
+	current.UpdatedAt = time.Now()
+
+	if current.Attempts >= m.policy.MaxAttempts {
+		current.State = StateFailed
+		m.persistAndNotify(current)
+		return
+	}
+
+	current.State = StateQueued
+	m.persistAndNotify(current)
+
+	backoff := m.policy.backoffFor(current.Attempts)
+	time.AfterFunc(backoff, func() {
+		m.enqueue(current)
+	})
+}
+
+func (m *Manager) print(job Job) error {
+	if job.Format == "escpos" {
+		return printer.PrintEscPos(job.PrinterName, string(job.Payload))
+	}
+	return printer.PrintText(job.PrinterName, string(job.Payload))
+}
+
+func (m *Manager) persistAndNotify(job Job) {
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	if err := m.store.Save(job); err != nil {
+		fmt.Printf("queue: failed to persist job %s: %v\n", job.ID, err)
+	}
+	if m.OnStateChange != nil {
+		m.OnStateChange(job)
+	}
+}
@@ -0,0 +1,157 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeStore is an in-memory Store for exercising Manager without a real
+// BoltDB file, with an injectable Save failure.
+type fakeStore struct {
+	jobs    map[string]Job
+	saveErr error
+	deleted []string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{jobs: make(map[string]Job)}
+}
+
+func (f *fakeStore) Save(job Job) error {
+	if f.saveErr != nil {
+		return f.saveErr
+	}
+	f.jobs[job.ID] = job
+	return nil
+}
+
+func (f *fakeStore) Delete(id string) error {
+	f.deleted = append(f.deleted, id)
+	delete(f.jobs, id)
+	return nil
+}
+
+func (f *fakeStore) Load() ([]Job, error) {
+	jobs := make([]Job, 0, len(f.jobs))
+	for _, j := range f.jobs {
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+func TestSubmitIsIdempotentByJobID(t *testing.T) {
+	m, err := NewManager(newFakeStore(), DefaultRetryPolicy())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	first, err := m.Submit(Job{ID: "job-1", PrinterName: "Kitchen", Format: "text", Payload: []byte("hi")})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if first.State != StateQueued {
+		t.Fatalf("state = %s, want %s", first.State, StateQueued)
+	}
+
+	second, err := m.Submit(Job{ID: "job-1", PrinterName: "Kitchen", Format: "text", Payload: []byte("a different payload")})
+	if err != nil {
+		t.Fatalf("resubmit: %v", err)
+	}
+	if string(second.Payload) != "hi" || second.CreatedAt != first.CreatedAt {
+		t.Fatalf("resubmitting a known jobID must return the original job unchanged, got %+v", second)
+	}
+}
+
+func TestSubmitRollsBackOnSaveFailure(t *testing.T) {
+	store := newFakeStore()
+	m, err := NewManager(store, DefaultRetryPolicy())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	store.saveErr = errors.New("disk full")
+	if _, err := m.Submit(Job{ID: "job-2", PrinterName: "Kitchen", Format: "text", Payload: []byte("x")}); err == nil {
+		t.Fatal("expected Submit to report the persistence failure")
+	}
+	if _, ok := m.Get("job-2"); ok {
+		t.Fatal("a failed Submit must not leave a zombie queued-forever entry in the in-memory index")
+	}
+
+	store.saveErr = nil
+	job, err := m.Submit(Job{ID: "job-2", PrinterName: "Kitchen", Format: "text", Payload: []byte("x")})
+	if err != nil {
+		t.Fatalf("retrying the same jobID once the store recovers should succeed: %v", err)
+	}
+	if job.State != StateQueued {
+		t.Fatalf("state = %s, want %s", job.State, StateQueued)
+	}
+}
+
+func TestCancelUnknownJob(t *testing.T) {
+	m, err := NewManager(newFakeStore(), DefaultRetryPolicy())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if err := m.Cancel("does-not-exist"); err == nil {
+		t.Fatal("expected an error cancelling an unknown job")
+	}
+}
+
+func TestCancelRejectsTerminalJob(t *testing.T) {
+	store := newFakeStore()
+	store.jobs["done-1"] = Job{ID: "done-1", PrinterName: "Kitchen", State: StateDone, UpdatedAt: time.Now()}
+
+	m, err := NewManager(store, DefaultRetryPolicy())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if err := m.Cancel("done-1"); err == nil {
+		t.Fatal("expected an error cancelling a job that already reached a terminal state")
+	}
+}
+
+func TestPruneRemovesOldTerminalJobsOnly(t *testing.T) {
+	store := newFakeStore()
+	store.jobs["old-done"] = Job{ID: "old-done", PrinterName: "Kitchen", State: StateDone, UpdatedAt: time.Now().Add(-48 * time.Hour)}
+	store.jobs["recent-done"] = Job{ID: "recent-done", PrinterName: "Kitchen", State: StateDone, UpdatedAt: time.Now()}
+	store.jobs["old-queued"] = Job{ID: "old-queued", PrinterName: "Kitchen", State: StateQueued, UpdatedAt: time.Now().Add(-48 * time.Hour)}
+
+	m, err := NewManager(store, DefaultRetryPolicy())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	m.prune(24 * time.Hour)
+
+	if _, ok := m.Get("old-done"); ok {
+		t.Fatal("a stale terminal job should have been pruned from the in-memory index")
+	}
+	if _, ok := m.Get("recent-done"); !ok {
+		t.Fatal("a terminal job within the retention window must not be pruned")
+	}
+	if _, ok := m.Get("old-queued"); !ok {
+		t.Fatal("a queued job must never be pruned regardless of age")
+	}
+
+	deleted := false
+	for _, id := range store.deleted {
+		if id == "old-done" {
+			deleted = true
+		}
+	}
+	if !deleted {
+		t.Fatal("prune must delete stale terminal jobs from the store, not just the in-memory index")
+	}
+}
+
+func TestRetryPolicyBackoffDoublesPerAttempt(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseBackoff: time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+	for i, w := range want {
+		attempt := i + 1
+		if got := p.backoffFor(attempt); got != w {
+			t.Errorf("backoffFor(%d) = %v, want %v", attempt, got, w)
+		}
+	}
+}
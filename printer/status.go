@@ -0,0 +1,108 @@
+package printer
+
+import "runtime"
+
+// StatusCondition enumerates the individual fault/state bits a printer
+// can report, decoded either from the OS spooler's native status or from
+// the Brother/Epson vendor status byte convention used by many thermal
+// printers over raw connections.
+type StatusCondition int
+
+const (
+	StatusOK StatusCondition = iota
+	StatusPaperOut
+	StatusPaperNearEnd
+	StatusCoverOpen
+	StatusCutterJam
+	StatusDrawerOpen
+	StatusOffline
+	StatusPrinting
+	StatusCommunicationError
+	StatusUnknownError
+)
+
+// conditionText maps each StatusCondition to its human-readable label.
+var conditionText = map[StatusCondition]string{
+	StatusOK:                 "ok",
+	StatusPaperOut:           "paper out",
+	StatusPaperNearEnd:       "paper near end",
+	StatusCoverOpen:          "cover open",
+	StatusCutterJam:          "cutter jam",
+	StatusDrawerOpen:         "drawer open",
+	StatusOffline:            "offline",
+	StatusPrinting:           "printing",
+	StatusCommunicationError: "communication error",
+	StatusUnknownError:       "unknown error",
+}
+
+func (s StatusCondition) String() string {
+	if t, ok := conditionText[s]; ok {
+		return t
+	}
+	return "unknown error"
+}
+
+// PrinterStatus is the decoded, platform-independent state of a single
+// printer, returned by GetPrinterStatus and pushed over the websocket by
+// subscribe_status.
+type PrinterStatus struct {
+	Name       string            `json:"name"`
+	Online     bool              `json:"online"`
+	Conditions []StatusCondition `json:"conditions"`
+	Messages   []string          `json:"messages,omitempty"`
+}
+
+// ConditionStrings renders Conditions as human-readable labels.
+func (s PrinterStatus) ConditionStrings() []string {
+	out := make([]string, 0, len(s.Conditions))
+	for _, c := range s.Conditions {
+		out = append(out, c.String())
+	}
+	return out
+}
+
+// GetPrinterStatus queries the current state of printerName.
+func GetPrinterStatus(printerName string) (PrinterStatus, error) {
+	if runtime.GOOS == "windows" {
+		return getPrinterStatusWindows(printerName)
+	}
+	return getPrinterStatusUnix(printerName)
+}
+
+// DecodeStatusBits decodes two vendor status bytes using the Brother/
+// Epson convention for ESC/POS "real-time status" responses: byte1
+// carries media/mechanism faults (bit 0 = no media, bit 1 = end of
+// media, bit 2 = cutter jam, bit 4 = printer in use), byte2 carries
+// cover/drawer/communication faults (bit 0 = cover open, bit 3 =
+// communication error, bit 4 = drawer kick-out connector pin 3 high,
+// i.e. cash drawer open).
+func DecodeStatusBits(byte1, byte2 byte) []StatusCondition {
+	var conditions []StatusCondition
+
+	if byte1&0x01 != 0 {
+		conditions = append(conditions, StatusPaperOut)
+	}
+	if byte1&0x02 != 0 {
+		conditions = append(conditions, StatusPaperNearEnd)
+	}
+	if byte1&0x04 != 0 {
+		conditions = append(conditions, StatusCutterJam)
+	}
+	if byte1&0x10 != 0 {
+		conditions = append(conditions, StatusPrinting)
+	}
+	if byte2&0x01 != 0 {
+		conditions = append(conditions, StatusCoverOpen)
+	}
+	if byte2&0x08 != 0 {
+		conditions = append(conditions, StatusCommunicationError)
+	}
+	if byte2&0x10 != 0 {
+		conditions = append(conditions, StatusDrawerOpen)
+	}
+
+	if len(conditions) == 0 {
+		conditions = append(conditions, StatusOK)
+	}
+	return conditions
+}
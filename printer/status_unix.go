@@ -0,0 +1,72 @@
+//go:build !windows
+// +build !windows
+
+package printer
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// getPrinterStatusWindows is a stub for non-Windows platforms; the
+// dispatch in GetPrinterStatus never reaches it there.
+func getPrinterStatusWindows(printerName string) (PrinterStatus, error) {
+	return PrinterStatus{}, fmt.Errorf("Win32 GetPrinterW status only available on Windows")
+}
+
+// getPrinterStatusUnix parses `lpstat -p <name> -l`, which CUPS renders
+// as a free-text description plus printer-state-reasons keywords (e.g.
+// "media-empty-warning", "cover-open", "marker-supply-low-report").
+func getPrinterStatusUnix(printerName string) (PrinterStatus, error) {
+	cmd := exec.Command("lpstat", "-p", printerName, "-l")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return PrinterStatus{}, fmt.Errorf("lpstat failed for '%s': %v", printerName, err)
+	}
+
+	status := PrinterStatus{Name: printerName, Online: true}
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "printer") && strings.Contains(line, "disabled"):
+			status.Online = false
+			status.Conditions = append(status.Conditions, StatusOffline)
+		case isStateReason(line):
+			status.Messages = append(status.Messages, line)
+			status.Conditions = append(status.Conditions, conditionForReason(line))
+		}
+	}
+
+	if len(status.Conditions) == 0 {
+		status.Conditions = append(status.Conditions, StatusOK)
+	}
+	return status, nil
+}
+
+// isStateReason reports whether line looks like a CUPS
+// printer-state-reasons keyword (e.g. "media-empty-warning").
+func isStateReason(line string) bool {
+	return strings.Contains(line, "-error") || strings.Contains(line, "-warning") || strings.Contains(line, "-report")
+}
+
+// conditionForReason maps a CUPS printer-state-reasons keyword to the
+// closest StatusCondition.
+func conditionForReason(reason string) StatusCondition {
+	switch {
+	case strings.Contains(reason, "media-empty"):
+		return StatusPaperOut
+	case strings.Contains(reason, "media-low"):
+		return StatusPaperNearEnd
+	case strings.Contains(reason, "cover-open"), strings.Contains(reason, "door-open"):
+		return StatusCoverOpen
+	case strings.Contains(reason, "cutter"), strings.Contains(reason, "jam"):
+		return StatusCutterJam
+	case strings.Contains(reason, "offline"), strings.Contains(reason, "shutdown"):
+		return StatusOffline
+	case strings.Contains(reason, "connecting-to-device"):
+		return StatusCommunicationError
+	default:
+		return StatusUnknownError
+	}
+}
@@ -0,0 +1,127 @@
+//go:build windows
+// +build windows
+
+package printer
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+var getPrinterW = winspool.NewProc("GetPrinterW")
+
+// PRINTER_INFO_2 mirrors the subset of the Win32 PRINTER_INFO_2
+// structure needed to read a printer's Status field.
+type PRINTER_INFO_2 struct {
+	PServerName         *uint16
+	PPrinterName        *uint16
+	PShareName          *uint16
+	PPortName           *uint16
+	PDriverName         *uint16
+	PComment            *uint16
+	PLocation           *uint16
+	PDevMode            uintptr
+	PSepFile            *uint16
+	PPrintProcessor     *uint16
+	PDatatype           *uint16
+	PParameters         *uint16
+	PSecurityDescriptor uintptr
+	Attributes          uint32
+	Priority            uint32
+	DefaultPriority     uint32
+	StartTime           uint32
+	UntilTime           uint32
+	Status              uint32
+	CJobs               uint32
+	AveragePPM          uint32
+}
+
+// Win32 PRINTER_STATUS_* bitmask values relevant to thermal/POS printers.
+const (
+	winStatusError    = 0x00000002
+	winStatusPaperJam = 0x00000008
+	winStatusPaperOut = 0x00000010
+	winStatusOffline  = 0x00000080
+	winStatusPrinting = 0x00000400
+	winStatusDoorOpen = 0x00400000
+)
+
+// getPrinterStatusUnix is a stub for Windows; the dispatch in
+// GetPrinterStatus never reaches it there.
+func getPrinterStatusUnix(printerName string) (PrinterStatus, error) {
+	return PrinterStatus{}, fmt.Errorf("lpstat status only available on non-Windows platforms")
+}
+
+// getPrinterStatusWindows queries PRINTER_INFO_2 via GetPrinterW and
+// decodes its Status bitmask.
+func getPrinterStatusWindows(printerName string) (PrinterStatus, error) {
+	printerNameUTF16, err := stringToUTF16Ptr(printerName)
+	if err != nil {
+		return PrinterStatus{}, fmt.Errorf("failed to convert printer name to UTF-16: %v", err)
+	}
+
+	var hPrinter uintptr
+	ret, _, err := openPrinterW.Call(
+		uintptr(unsafe.Pointer(printerNameUTF16)),
+		uintptr(unsafe.Pointer(&hPrinter)),
+		uintptr(0),
+	)
+	if ret == 0 {
+		return PrinterStatus{}, fmt.Errorf("OpenPrinterW failed for printer '%s': %v", printerName, err)
+	}
+	defer closePrinter.Call(hPrinter)
+
+	var neededBytes uint32
+	getPrinterW.Call(hPrinter, 2, 0, 0, uintptr(unsafe.Pointer(&neededBytes)))
+	if neededBytes == 0 {
+		return PrinterStatus{}, fmt.Errorf("GetPrinterW failed to report buffer size for '%s'", printerName)
+	}
+
+	buf := make([]byte, neededBytes)
+	var bytesNeeded uint32
+	ret, _, err = getPrinterW.Call(
+		hPrinter,
+		2,
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(neededBytes),
+		uintptr(unsafe.Pointer(&bytesNeeded)),
+	)
+	if ret == 0 {
+		return PrinterStatus{}, fmt.Errorf("GetPrinterW failed for printer '%s': %v", printerName, err)
+	}
+
+	info := (*PRINTER_INFO_2)(unsafe.Pointer(&buf[0]))
+	return PrinterStatus{
+		Name:       printerName,
+		Online:     info.Status&winStatusOffline == 0,
+		Conditions: decodeWindowsStatus(info.Status),
+	}, nil
+}
+
+func decodeWindowsStatus(status uint32) []StatusCondition {
+	var conditions []StatusCondition
+
+	if status&winStatusPaperOut != 0 {
+		conditions = append(conditions, StatusPaperOut)
+	}
+	if status&winStatusPaperJam != 0 {
+		conditions = append(conditions, StatusCutterJam)
+	}
+	if status&winStatusDoorOpen != 0 {
+		conditions = append(conditions, StatusCoverOpen)
+	}
+	if status&winStatusOffline != 0 {
+		conditions = append(conditions, StatusOffline)
+	}
+	if status&winStatusPrinting != 0 {
+		conditions = append(conditions, StatusPrinting)
+	}
+	if status&winStatusError != 0 {
+		conditions = append(conditions, StatusUnknownError)
+	}
+
+	if len(conditions) == 0 {
+		conditions = append(conditions, StatusOK)
+	}
+	return conditions
+}
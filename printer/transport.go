@@ -0,0 +1,74 @@
+package printer
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// transportSchemes are the URI schemes Dispatch recognizes. Anything else
+// (including a bare spooler name that happens to contain a colon, which
+// is legal on both CUPS and Windows, e.g. "Kitchen:Printer1") is treated
+// as an OS spooler name rather than a URI.
+var transportSchemes = map[string]bool{
+	"spooler": true,
+	"raw":     true,
+	"ipp":     true,
+	"ipps":    true,
+}
+
+// Dispatch sends data (typically compiled ESC/POS bytes) to the printer
+// identified by target, which may be a bare OS spooler name (the
+// pre-existing behavior) or one of the following URIs:
+//
+//	spooler://<name>     OS print spooler
+//	raw://<host>:<port>  HP JetDirect / RAW 9100, ESC/POS written directly over TCP
+//	ipp://<host>/<path>  Internet Printing Protocol
+//	ipps://<host>/<path> IPP over TLS
+//
+// This unblocks headless deployments where the printer is on the LAN
+// with no OS spooler entry, such as Raspberry Pi kiosks.
+func Dispatch(target string, data []byte) error {
+	if !strings.Contains(target, "://") {
+		return printEscPosSpooler(target, string(data))
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || !transportSchemes[u.Scheme] {
+		return printEscPosSpooler(target, string(data))
+	}
+
+	switch u.Scheme {
+	case "spooler":
+		return printEscPosSpooler(u.Host, string(data))
+	case "raw":
+		return sendRaw9100(u.Host, data)
+	case "ipp", "ipps":
+		return PrintIPP(target, data)
+	default:
+		return fmt.Errorf("unsupported printer transport scheme %q", u.Scheme)
+	}
+}
+
+// sendRaw9100 writes data directly to a JetDirect-style RAW printing
+// port (conventionally TCP 9100). Most thermal and label printers accept
+// raw ESC/POS bytes on this port with no protocol framing.
+func sendRaw9100(hostPort string, data []byte) error {
+	if _, _, err := net.SplitHostPort(hostPort); err != nil {
+		hostPort = net.JoinHostPort(hostPort, "9100")
+	}
+
+	conn, err := net.DialTimeout("tcp", hostPort, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("dial raw printer %s: %w", hostPort, err)
+	}
+	defer conn.Close()
+
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := conn.Write(data); err != nil {
+		return fmt.Errorf("write to raw printer %s: %w", hostPort, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package websocket
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"printer-service/config"
+)
+
+// serverConfig holds the config.Config passed to Configure. It is nil
+// until Configure is called, in which case origin checking and
+// authentication are both disabled (the permissive development default).
+var serverConfig *config.Config
+
+// Configure wires cfg's security settings (TLS, allowed origins, auth
+// tokens) into the websocket package. It must be called once before
+// HandleWebSocket starts serving requests.
+func Configure(cfg *config.Config) {
+	serverConfig = cfg
+}
+
+// isOriginAllowed reports whether r's Origin header is acceptable. With
+// no AllowedOrigins configured, or no Origin header at all (non-browser
+// clients), every request is allowed.
+func isOriginAllowed(r *http.Request) bool {
+	if serverConfig == nil || len(serverConfig.AllowedOrigins) == 0 {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range serverConfig.AllowedOrigins {
+		if allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate checks r for a valid bearer token, either in the
+// Authorization header ("Bearer <token>") or the token query parameter.
+// It returns the matching AuthToken and true on success. With no
+// AuthTokens configured, authentication is disabled and every request
+// succeeds with a zero-value AuthToken (unscoped).
+func authenticate(r *http.Request) (config.AuthToken, bool) {
+	if serverConfig == nil || len(serverConfig.AuthTokens) == 0 {
+		return config.AuthToken{}, true
+	}
+
+	presented := bearerToken(r)
+	if presented == "" {
+		return config.AuthToken{}, false
+	}
+
+	for _, t := range serverConfig.AuthTokens {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(t.Token)) == 1 {
+			return t, true
+		}
+	}
+	return config.AuthToken{}, false
+}
+
+func bearerToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); len(auth) > len("Bearer ") && auth[:len("Bearer ")] == "Bearer " {
+		return auth[len("Bearer "):]
+	}
+	return r.URL.Query().Get("token")
+}
+
+// authorizedFor reports whether the client's token scope permits
+// printing to printerName. An empty scope (no auth configured, or a
+// token with no PrinterName) permits any printer.
+func (c *Client) authorizedFor(printerName string) bool {
+	return c.scopedPrinter == "" || c.scopedPrinter == printerName
+}
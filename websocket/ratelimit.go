@@ -0,0 +1,48 @@
+package websocket
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a simple token-bucket limiter used to cap how many
+// print messages a single connection can submit per second, so one
+// misbehaving client can't monopolize the print queue.
+type rateLimiter struct {
+	mu       sync.Mutex
+	tokens   float64
+	capacity float64
+	refill   float64 // tokens added per second
+	last     time.Time
+}
+
+func newRateLimiter(ratePerSecond float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		tokens:   float64(burst),
+		capacity: float64(burst),
+		refill:   ratePerSecond,
+		last:     time.Now(),
+	}
+}
+
+// Allow reports whether a request may proceed now, consuming one token
+// if so.
+func (r *rateLimiter) Allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(r.last).Seconds()
+	r.last = now
+
+	r.tokens += elapsed * r.refill
+	if r.tokens > r.capacity {
+		r.tokens = r.capacity
+	}
+
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
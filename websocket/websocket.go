@@ -1,11 +1,23 @@
 package websocket
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
 	"log"
 	"net/http"
 	"printer-service/printer"
+	"printer-service/printer/escpos"
+	"printer-service/printer/queue"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -16,30 +28,139 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+var jobManager *queue.Manager
+
+// InitQueue opens the persistent job queue at dbPath and wires its state
+// changes to broadcast job_state events to all connected clients. It
+// must be called once before HandleWebSocket starts serving requests.
+func InitQueue(dbPath string) error {
+	store, err := queue.NewBoltStore(dbPath)
+	if err != nil {
+		return fmt.Errorf("open job queue store: %w", err)
+	}
+
+	manager, err := queue.NewManager(store, queue.DefaultRetryPolicy())
+	if err != nil {
+		return fmt.Errorf("create job queue manager: %w", err)
+	}
+
+	manager.OnStateChange = func(job queue.Job) {
+		broadcast(Message{Type: "job_state", Payload: mustMarshal(job)})
+	}
+
+	jobManager = manager
+	return nil
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = make(map[*Client]struct{})
+)
+
+func registerClient(c *Client) {
+	clientsMu.Lock()
+	clients[c] = struct{}{}
+	clientsMu.Unlock()
+}
+
+func unregisterClient(c *Client) {
+	clientsMu.Lock()
+	delete(clients, c)
+	clientsMu.Unlock()
+}
+
+func broadcast(msg Message) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	for c := range clients {
+		c.sendMessage(msg)
+	}
+}
+
 type Message struct {
 	Type    string          `json:"type"`
 	Payload json.RawMessage `json:"payload"`
 }
 
-type PrintJobEscPos struct {
-	PrinterName string      `json:"printerName"`
-	JobID       string      `json:"jobId"`
-	Data        interface{} `json:"data"`   // Keep as interface{} for backward compatibility
-	Format      string      `json:"format"` // "text", "escpos", "pdf"
-}
-
 type RawEscPosJob struct {
 	PrinterName string `json:"printerName"`
 	JobID       string `json:"jobId"`
 	RawData     string `json:"rawData"` // Raw ESC/POS commands
 }
 
+// EscPosJob is the payload for the print_escpos message: a structured
+// document that the server compiles into ESC/POS bytes via the escpos
+// builder, rather than raw bytes generated by the frontend.
+type EscPosJob struct {
+	PrinterName string          `json:"printerName"`
+	JobID       string          `json:"jobId"`
+	Document    escpos.Document `json:"document"`
+}
+
+// PrintImageJob is the payload for the print_image message: a base64
+// encoded PNG or JPEG plus the rasterization options to apply.
+type PrintImageJob struct {
+	PrinterName string               `json:"printerName"`
+	JobID       string               `json:"jobId"`
+	ImageData   string               `json:"imageData"` // base64-encoded PNG or JPEG
+	Options     PrintImageJobOptions `json:"options"`
+}
+
+// PrintImageJobOptions mirrors printer.ImageOptions using JSON-friendly
+// field types.
+type PrintImageJobOptions struct {
+	WidthDots int    `json:"widthDots"`
+	Dither    string `json:"dither"` // "floyd-steinberg" (default), "atkinson", "threshold"
+	Threshold int    `json:"threshold"`
+	Rotation  int    `json:"rotation"`
+	Codepage  string `json:"codepage"`
+}
+
+// SubscribeStatusRequest is the payload for the subscribe_status message.
+type SubscribeStatusRequest struct {
+	PrinterName     string `json:"printerName"`
+	IntervalSeconds int    `json:"intervalSeconds"`
+}
+
+const defaultStatusPollInterval = 5 * time.Second
+
 type Client struct {
-	conn *websocket.Conn
-	send chan []byte
+	conn   *websocket.Conn
+	send   chan []byte
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	scopedPrinter string
+	limiter       *rateLimiter
+
+	statusSubsMu sync.Mutex
+	statusSubs   map[string]context.CancelFunc
 }
 
+// printRateLimit bounds how many print-type messages a single connection
+// may submit per second, with a small burst allowance.
+const (
+	printRateLimitPerSecond = 5.0
+	printRateLimitBurst     = 10
+)
+
+// maxStatusSubscriptions bounds how many distinct printers a single
+// connection may run a pollStatus goroutine for, so a client can't fork
+// unbounded goroutines/OS processes by spamming subscribe_status.
+const maxStatusSubscriptions = 16
+
 func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !isOriginAllowed(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	token, ok := authenticate(r)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade error: %v", err)
@@ -47,17 +168,27 @@ func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
+	ctx, cancel := context.WithCancel(context.Background())
 	client := &Client{
-		conn: conn,
-		send: make(chan []byte, 256),
+		conn:          conn,
+		send:          make(chan []byte, 256),
+		ctx:           ctx,
+		cancel:        cancel,
+		scopedPrinter: token.PrinterName,
+		limiter:       newRateLimiter(printRateLimitPerSecond, printRateLimitBurst),
+		statusSubs:    make(map[string]context.CancelFunc),
 	}
 
+	registerClient(client)
+	defer unregisterClient(client)
+
 	go client.writePump()
 	client.readPump()
 }
 
 func (c *Client) readPump() {
 	defer close(c.send)
+	defer c.cancel()
 
 	for {
 		var msg Message
@@ -70,6 +201,23 @@ func (c *Client) readPump() {
 	}
 }
 
+// sendMessage marshals and queues msg for delivery via writePump. All
+// outgoing messages go through this path (rather than writing to the
+// connection directly) because subscribe_status and other background
+// pushes write concurrently with the read loop's own responses, and
+// gorilla/websocket only supports one writer at a time.
+func (c *Client) sendMessage(msg Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("failed to marshal message: %v", err)
+		return
+	}
+	select {
+	case c.send <- data:
+	case <-c.ctx.Done():
+	}
+}
+
 func (c *Client) writePump() {
 	for message := range c.send {
 		err := c.conn.WriteMessage(websocket.TextMessage, message)
@@ -79,8 +227,26 @@ func (c *Client) writePump() {
 	}
 }
 
+// rateLimitedMessageTypes share the connection's print rate limit: the
+// print_* messages because they consume a printer, and subscribe_status
+// because each one can spawn a polling goroutine that shells out to the
+// OS on a timer.
+var rateLimitedMessageTypes = map[string]bool{
+	"print":            true,
+	"print_escpos":     true,
+	"print_raw_escpos": true,
+	"print_image":      true,
+	"subscribe_status": true,
+}
+
 func (c *Client) handleMessage(msg Message) {
 	fmt.Printf("Received message: %s\n", msg.Type)
+
+	if rateLimitedMessageTypes[msg.Type] && !c.limiter.Allow() {
+		c.sendError("Rate limit exceeded, slow down")
+		return
+	}
+
 	switch msg.Type {
 	case "get_printers":
 		c.sendPrinters()
@@ -90,9 +256,24 @@ func (c *Client) handleMessage(msg Message) {
 		c.handlePrintEscPos(msg.Payload)
 	case "print_raw_escpos":
 		c.handlePrintRawEscPos(msg.Payload)
+	case "print_image":
+		c.handlePrintImage(msg.Payload)
+	case "subscribe_status":
+		c.handleSubscribeStatus(msg.Payload)
+	case "cancel_job":
+		c.handleCancelJob(msg.Payload)
+	case "get_job":
+		c.handleGetJob(msg.Payload)
+	case "list_jobs":
+		c.handleListJobs(msg.Payload)
 	}
 }
 
+// networkDiscoveryTimeout bounds how long sendPrinters waits for mDNS
+// responses from network-attached printers before replying with whatever
+// was found.
+const networkDiscoveryTimeout = 2 * time.Second
+
 func (c *Client) sendPrinters() {
 	printers, err := printer.DetectPrinters()
 	if err != nil {
@@ -100,12 +281,44 @@ func (c *Client) sendPrinters() {
 		return
 	}
 
+	if networkPrinters, err := printer.DiscoverNetworkPrinters(networkDiscoveryTimeout); err != nil {
+		log.Printf("mdns printer discovery failed: %v", err)
+	} else {
+		printers = append(printers, networkPrinters...)
+	}
+
 	response := Message{
 		Type:    "printers_list",
 		Payload: mustMarshal(printers),
 	}
 
-	c.conn.WriteJSON(response)
+	c.sendMessage(response)
+}
+
+// submitJob queues a job with jobManager, assigning a generated ID if the
+// client didn't supply one. Resubmitting the same non-empty jobID is
+// idempotent: the already-known job is returned unchanged.
+func submitJob(jobID, printerName, format string, payload []byte) (queue.Job, error) {
+	if jobManager == nil {
+		return queue.Job{}, fmt.Errorf("job queue not initialized")
+	}
+	if jobID == "" {
+		jobID = generateJobID()
+	}
+	return jobManager.Submit(queue.Job{
+		ID:          jobID,
+		PrinterName: printerName,
+		Format:      format,
+		Payload:     payload,
+	})
+}
+
+func generateJobID() string {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return fmt.Sprintf("job-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf[:])
 }
 
 func (c *Client) handlePrint(payload json.RawMessage) {
@@ -114,24 +327,54 @@ func (c *Client) handlePrint(payload json.RawMessage) {
 		c.sendError("Invalid print job format")
 		return
 	}
+	if !c.authorizedFor(printJob.PrinterName) {
+		c.sendError("Not authorized for this printer")
+		return
+	}
 
-	err := printer.PrintText(printJob.PrinterName, printJob.Content)
+	job, err := submitJob(printJob.JobID, printJob.PrinterName, "text", []byte(printJob.Content))
 	if err != nil {
-		c.sendError(fmt.Sprintf("Print failed: %v", err))
+		c.sendError(fmt.Sprintf("Failed to queue print job: %v", err))
 		return
 	}
 
 	response := Message{
 		Type:    "print_success",
-		Payload: mustMarshal(map[string]string{"jobId": printJob.JobID}),
+		Payload: mustMarshal(map[string]string{"jobId": job.ID}),
 	}
 
-	c.conn.WriteJSON(response)
+	c.sendMessage(response)
 }
 
 func (c *Client) handlePrintEscPos(payload json.RawMessage) {
-	// This endpoint is deprecated - use print_raw_escpos instead
-	c.sendError("This endpoint is deprecated. Use 'print_raw_escpos' with raw ESC/POS commands generated from frontend.")
+	var escPosJob EscPosJob
+	if err := json.Unmarshal(payload, &escPosJob); err != nil {
+		c.sendError("Invalid ESC/POS print job format")
+		return
+	}
+	if !c.authorizedFor(escPosJob.PrinterName) {
+		c.sendError("Not authorized for this printer")
+		return
+	}
+
+	data, err := escPosJob.Document.Build()
+	if err != nil {
+		c.sendError(fmt.Sprintf("Invalid ESC/POS document: %v", err))
+		return
+	}
+
+	job, err := submitJob(escPosJob.JobID, escPosJob.PrinterName, "escpos", data)
+	if err != nil {
+		c.sendError(fmt.Sprintf("Failed to queue ESC/POS print job: %v", err))
+		return
+	}
+
+	response := Message{
+		Type:    "print_escpos_success",
+		Payload: mustMarshal(map[string]string{"jobId": job.ID}),
+	}
+
+	c.sendMessage(response)
 }
 
 func (c *Client) handlePrintRawEscPos(payload json.RawMessage) {
@@ -140,19 +383,240 @@ func (c *Client) handlePrintRawEscPos(payload json.RawMessage) {
 		c.sendError("Invalid raw ESC/POS print job format")
 		return
 	}
+	if !c.authorizedFor(printJob.PrinterName) {
+		c.sendError("Not authorized for this printer")
+		return
+	}
 
-	err := printer.PrintEscPos(printJob.PrinterName, printJob.RawData)
+	job, err := submitJob(printJob.JobID, printJob.PrinterName, "escpos", []byte(printJob.RawData))
 	if err != nil {
-		c.sendError(fmt.Sprintf("Raw ESC/POS print failed: %v", err))
+		c.sendError(fmt.Sprintf("Failed to queue raw ESC/POS print job: %v", err))
 		return
 	}
 
 	response := Message{
 		Type:    "raw_escpos_print_success",
-		Payload: mustMarshal(map[string]string{"jobId": printJob.JobID}),
+		Payload: mustMarshal(map[string]string{"jobId": job.ID}),
 	}
 
-	c.conn.WriteJSON(response)
+	c.sendMessage(response)
+}
+
+// jobIDRequest is the payload shared by cancel_job and get_job.
+type jobIDRequest struct {
+	JobID string `json:"jobId"`
+}
+
+func (c *Client) handleCancelJob(payload json.RawMessage) {
+	var req jobIDRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		c.sendError("Invalid cancel_job request format")
+		return
+	}
+	if jobManager == nil {
+		c.sendError("Job queue not initialized")
+		return
+	}
+	job, ok := jobManager.Get(req.JobID)
+	if !ok {
+		c.sendError(fmt.Sprintf("Unknown job %s", req.JobID))
+		return
+	}
+	if !c.authorizedFor(job.PrinterName) {
+		c.sendError("Not authorized for this printer")
+		return
+	}
+	if err := jobManager.Cancel(req.JobID); err != nil {
+		c.sendError(fmt.Sprintf("Failed to cancel job: %v", err))
+		return
+	}
+
+	c.sendMessage(Message{
+		Type:    "cancel_job_success",
+		Payload: mustMarshal(map[string]string{"jobId": req.JobID}),
+	})
+}
+
+func (c *Client) handleGetJob(payload json.RawMessage) {
+	var req jobIDRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		c.sendError("Invalid get_job request format")
+		return
+	}
+	if jobManager == nil {
+		c.sendError("Job queue not initialized")
+		return
+	}
+	job, ok := jobManager.Get(req.JobID)
+	if !ok {
+		c.sendError(fmt.Sprintf("Unknown job %s", req.JobID))
+		return
+	}
+	if !c.authorizedFor(job.PrinterName) {
+		c.sendError("Not authorized for this printer")
+		return
+	}
+
+	c.sendMessage(Message{Type: "job_state", Payload: mustMarshal(job)})
+}
+
+func (c *Client) handleListJobs(payload json.RawMessage) {
+	if jobManager == nil {
+		c.sendError("Job queue not initialized")
+		return
+	}
+
+	jobs := jobManager.List()
+	if c.scopedPrinter != "" {
+		scoped := make([]queue.Job, 0, len(jobs))
+		for _, job := range jobs {
+			if job.PrinterName == c.scopedPrinter {
+				scoped = append(scoped, job)
+			}
+		}
+		jobs = scoped
+	}
+
+	c.sendMessage(Message{Type: "jobs_list", Payload: mustMarshal(jobs)})
+}
+
+func (c *Client) handlePrintImage(payload json.RawMessage) {
+	var job PrintImageJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		c.sendError("Invalid print image job format")
+		return
+	}
+	if !c.authorizedFor(job.PrinterName) {
+		c.sendError("Not authorized for this printer")
+		return
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(job.ImageData)
+	if err != nil {
+		c.sendError("Invalid base64 image data")
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		c.sendError(fmt.Sprintf("Invalid image: %v", err))
+		return
+	}
+
+	opts := printer.DefaultImageOptions()
+	if job.Options.WidthDots > 0 {
+		opts.WidthDots = job.Options.WidthDots
+	}
+	switch job.Options.Dither {
+	case "atkinson":
+		opts.Dither = printer.DitherAtkinson
+	case "threshold":
+		opts.Dither = printer.DitherThreshold
+	}
+	if job.Options.Threshold > 0 {
+		opts.Threshold = uint8(job.Options.Threshold)
+	}
+	opts.Rotation = job.Options.Rotation
+	if cp, ok := escpos.CodepageByName(job.Options.Codepage); ok {
+		opts.Codepage = cp
+	}
+
+	data, err := printer.BuildImageEscPos(img, opts)
+	if err != nil {
+		c.sendError(fmt.Sprintf("Print image failed: %v", err))
+		return
+	}
+
+	queuedJob, err := submitJob(job.JobID, job.PrinterName, "escpos", data)
+	if err != nil {
+		c.sendError(fmt.Sprintf("Failed to queue print image job: %v", err))
+		return
+	}
+
+	response := Message{
+		Type:    "print_image_success",
+		Payload: mustMarshal(map[string]string{"jobId": queuedJob.ID}),
+	}
+
+	c.sendMessage(response)
+}
+
+func (c *Client) handleSubscribeStatus(payload json.RawMessage) {
+	var req SubscribeStatusRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		c.sendError("Invalid subscribe_status request format")
+		return
+	}
+	if !c.authorizedFor(req.PrinterName) {
+		c.sendError("Not authorized for this printer")
+		return
+	}
+
+	interval := time.Duration(req.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultStatusPollInterval
+	}
+
+	c.statusSubsMu.Lock()
+	defer c.statusSubsMu.Unlock()
+
+	if _, subscribed := c.statusSubs[req.PrinterName]; subscribed {
+		return // already polling this printer for this connection
+	}
+	if len(c.statusSubs) >= maxStatusSubscriptions {
+		c.sendError("Too many active status subscriptions")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.statusSubs[req.PrinterName] = cancel
+	go c.pollStatus(ctx, req.PrinterName, interval)
+}
+
+// pollStatus polls printerName every interval and pushes a printer_status
+// event whenever the decoded status changes, until ctx is cancelled (the
+// connection closes, or handleSubscribeStatus isn't renewing this
+// subscription anymore).
+func (c *Client) pollStatus(ctx context.Context, printerName string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last printer.PrinterStatus
+	first := true
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			status, err := printer.GetPrinterStatus(printerName)
+			if err != nil {
+				continue
+			}
+			if !first && statusEqual(status, last) {
+				continue
+			}
+			first = false
+			last = status
+
+			c.sendMessage(Message{
+				Type:    "printer_status",
+				Payload: mustMarshal(status),
+			})
+		}
+	}
+}
+
+func statusEqual(a, b printer.PrinterStatus) bool {
+	if a.Online != b.Online || len(a.Conditions) != len(b.Conditions) {
+		return false
+	}
+	for i := range a.Conditions {
+		if a.Conditions[i] != b.Conditions[i] {
+			return false
+		}
+	}
+	return true
 }
 
 func (c *Client) sendError(message string) {
@@ -161,7 +625,7 @@ func (c *Client) sendError(message string) {
 		Payload: mustMarshal(map[string]string{"message": message}),
 	}
 
-	c.conn.WriteJSON(response)
+	c.sendMessage(response)
 }
 
 func mustMarshal(v interface{}) json.RawMessage {